@@ -0,0 +1,206 @@
+package hx711
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Ingredient is one step of a Recipe
+type Ingredient struct {
+	Name   string
+	Target float64
+	// Tolerance is how far from Target the actual amount can be and still be accepted
+	Tolerance float64
+}
+
+// Recipe is a list of ingredients weighed one after another into the same container
+type Recipe struct {
+	Name        string
+	Ingredients []Ingredient
+}
+
+// BatchStep is the record of weighing one ingredient
+type BatchStep struct {
+	Ingredient
+	// Actual is the settled net amount of the ingredient
+	Actual float64
+	// InTolerance is true when Actual is within Tolerance of Target
+	InTolerance bool
+	// Tare is the gross weight the ingredient was tared at
+	Tare  float64
+	Start time.Time
+	End   time.Time
+}
+
+// BatchRecord is the record of a batch
+type BatchRecord struct {
+	Recipe string
+	Steps  []BatchStep
+	Start  time.Time
+	End    time.Time
+	// Complete is true when all the ingredients have been weighed
+	Complete bool
+}
+
+// BatchProgress is the progress of the current step of a batch
+type BatchProgress struct {
+	// Step is the index of the current ingredient
+	Step       int
+	Ingredient Ingredient
+	// Taring is true while waiting for a stable reading to tare the step
+	Taring bool
+	// Net is the amount of the ingredient so far
+	Net float64
+	// Remaining is Target minus Net
+	Remaining float64
+	// Fraction is Net divided by Target
+	Fraction float64
+}
+
+// BatchEngine weighs the ingredients of a recipe into one container.
+// The scale is tared on a stable reading at the start of each step,
+// and a step is done when the stable net amount is within tolerance, or when Next is called.
+// Call Start, then pass it readings, such as from BackgroundReadings, with Update.
+type BatchEngine struct {
+	Recipe Recipe
+	// Stabilizer decides when the readings are stable for taring and accepting a step
+	Stabilizer Stabilizer
+	// Progress if set is called with the progress for each reading, from the goroutine calling Update
+	Progress func(BatchProgress)
+	// StepDone if set is called when a step is done, from the goroutine calling Update
+	StepDone func(BatchStep)
+
+	record  BatchRecord
+	step    int
+	taring  bool
+	tare    float64
+	started bool
+	net     float64
+}
+
+// Start starts a batch at startTime
+func (batchEngine *BatchEngine) Start(startTime time.Time) error {
+	if len(batchEngine.Recipe.Ingredients) < 1 {
+		return fmt.Errorf("recipe has no ingredients")
+	}
+	batchEngine.record = BatchRecord{
+		Recipe: batchEngine.Recipe.Name,
+		Steps:  make([]BatchStep, 0, len(batchEngine.Recipe.Ingredients)),
+		Start:  startTime,
+	}
+	batchEngine.step = 0
+	batchEngine.taring = true
+	batchEngine.started = true
+	batchEngine.Stabilizer.Reset()
+	return nil
+}
+
+// Update adds a reading. Readings need to be passed in time order.
+// Returns the progress of the current step and true when the batch is done.
+func (batchEngine *BatchEngine) Update(reading Reading) (BatchProgress, bool) {
+	if !batchEngine.started {
+		return BatchProgress{}, batchEngine.record.Complete
+	}
+
+	value, stable := batchEngine.Stabilizer.Update(reading)
+	ingredient := batchEngine.Recipe.Ingredients[batchEngine.step]
+
+	if batchEngine.taring {
+		if stable {
+			batchEngine.startStep(value, reading.Time)
+		}
+		progress := BatchProgress{Step: batchEngine.step, Ingredient: ingredient, Taring: batchEngine.taring, Remaining: ingredient.Target}
+		batchEngine.progress(progress)
+		return progress, false
+	}
+
+	batchEngine.net = reading.Value - batchEngine.tare
+	if stable {
+		batchEngine.net = value - batchEngine.tare
+	}
+
+	progress := BatchProgress{
+		Step:       batchEngine.step,
+		Ingredient: ingredient,
+		Net:        batchEngine.net,
+		Remaining:  ingredient.Target - batchEngine.net,
+	}
+	if ingredient.Target != 0 {
+		progress.Fraction = batchEngine.net / ingredient.Target
+	}
+	batchEngine.progress(progress)
+
+	if stable && math.Abs(batchEngine.net-ingredient.Target) <= ingredient.Tolerance {
+		batchEngine.finishStep(value, reading.Time)
+		if batchEngine.started {
+			// the stable gross weight is the tare of the next step
+			batchEngine.startStep(value, reading.Time)
+		}
+	}
+
+	return progress, batchEngine.record.Complete
+}
+
+// Next accepts the current step with the last net amount, even if not within tolerance.
+// The next step is tared on the next stable reading.
+func (batchEngine *BatchEngine) Next(nextTime time.Time) error {
+	if !batchEngine.started {
+		return fmt.Errorf("batch not started")
+	}
+	if batchEngine.taring {
+		return fmt.Errorf("step not tared yet")
+	}
+	batchEngine.finishStep(batchEngine.tare+batchEngine.net, nextTime)
+	if batchEngine.started {
+		batchEngine.taring = true
+		batchEngine.Stabilizer.Reset()
+	}
+	return nil
+}
+
+// startStep tares the current step at gross weight tare
+func (batchEngine *BatchEngine) startStep(tare float64, startTime time.Time) {
+	batchEngine.taring = false
+	batchEngine.tare = tare
+	batchEngine.net = 0
+	batchEngine.Stabilizer.Reset()
+	batchEngine.record.Steps = append(batchEngine.record.Steps, BatchStep{
+		Ingredient: batchEngine.Recipe.Ingredients[batchEngine.step],
+		Tare:       tare,
+		Start:      startTime,
+	})
+}
+
+// finishStep records the current step with gross weight and moves to the next ingredient
+func (batchEngine *BatchEngine) finishStep(gross float64, endTime time.Time) {
+	step := &batchEngine.record.Steps[len(batchEngine.record.Steps)-1]
+	step.Actual = gross - step.Tare
+	step.InTolerance = math.Abs(step.Actual-step.Target) <= step.Tolerance
+	step.End = endTime
+
+	if batchEngine.StepDone != nil {
+		batchEngine.StepDone(*step)
+	}
+
+	batchEngine.step++
+	if batchEngine.step >= len(batchEngine.Recipe.Ingredients) {
+		batchEngine.started = false
+		batchEngine.record.Complete = true
+		batchEngine.record.End = endTime
+	}
+}
+
+// progress calls Progress if set
+func (batchEngine *BatchEngine) progress(progress BatchProgress) {
+	if batchEngine.Progress != nil {
+		batchEngine.Progress(progress)
+	}
+}
+
+// Record returns the batch record so far
+func (batchEngine *BatchEngine) Record() BatchRecord {
+	record := batchEngine.record
+	record.Steps = append([]BatchStep(nil), batchEngine.record.Steps...)
+	return record
+}
@@ -0,0 +1,138 @@
+package hx711
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestBatchEngine(t *testing.T) {
+	recipe := Recipe{
+		Name: "mix",
+		Ingredients: []Ingredient{
+			{Name: "flour", Target: 50, Tolerance: 1},
+			{Name: "sugar", Target: 30, Tolerance: 1},
+		},
+	}
+
+	tests := []struct {
+		name string
+		// build adds the readings, calling next when Next should be called
+		build        func(stream *readingStream) (nextAt int)
+		wantActuals  []float64
+		wantInTols   []bool
+		wantTares    []float64
+		wantComplete bool
+	}{
+		{
+			name: "in tolerance",
+			build: func(stream *readingStream) int {
+				stream.hold(10, 10).ramp(60.5, 10).hold(60.5, 10).ramp(90, 10).hold(90, 10)
+				return -1
+			},
+			wantActuals:  []float64{50.5, 29.5},
+			wantInTols:   []bool{true, true},
+			wantTares:    []float64{10, 60.5},
+			wantComplete: true,
+		},
+		{
+			name: "next accepts out of tolerance",
+			build: func(stream *readingStream) int {
+				stream.hold(10, 10).ramp(55, 10).hold(55, 10)
+				nextAt := len(stream.readings)
+				stream.hold(55, 10).ramp(85, 10).hold(85, 10)
+				return nextAt
+			},
+			wantActuals:  []float64{45, 30},
+			wantInTols:   []bool{false, true},
+			wantTares:    []float64{10, 55},
+			wantComplete: true,
+		},
+		{
+			name: "not stable",
+			build: func(stream *readingStream) int {
+				stream.hold(10, 10).ramp(60, 10)
+				return -1
+			},
+			wantActuals:  []float64{0},
+			wantInTols:   []bool{false},
+			wantTares:    []float64{10},
+			wantComplete: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			batchEngine := &BatchEngine{
+				Recipe:     recipe,
+				Stabilizer: Stabilizer{Tolerance: 0.2, Duration: 500 * time.Millisecond},
+			}
+			var stepsDone int
+			batchEngine.StepDone = func(step BatchStep) {
+				stepsDone++
+			}
+
+			stream := &readingStream{interval: 100 * time.Millisecond}
+			nextAt := test.build(stream)
+
+			err := batchEngine.Start(testStart)
+			if err != nil {
+				t.Fatalf("Start error: %v", err)
+			}
+			var done bool
+			for i, reading := range stream.readings {
+				if i == nextAt {
+					err = batchEngine.Next(reading.Time)
+					if err != nil {
+						t.Fatalf("Next error: %v", err)
+					}
+				}
+				_, done = batchEngine.Update(reading)
+			}
+
+			record := batchEngine.Record()
+			if done != test.wantComplete || record.Complete != test.wantComplete {
+				t.Errorf("done got %v Complete got %v want %v", done, record.Complete, test.wantComplete)
+			}
+			if record.Recipe != recipe.Name {
+				t.Errorf("Recipe got %v want %v", record.Recipe, recipe.Name)
+			}
+			if len(record.Steps) != len(test.wantActuals) {
+				t.Fatalf("steps got %v want %v", len(record.Steps), len(test.wantActuals))
+			}
+			if test.wantComplete && stepsDone != len(test.wantActuals) {
+				t.Errorf("StepDone called %v times want %v", stepsDone, len(test.wantActuals))
+			}
+			for i, step := range record.Steps {
+				if math.Abs(step.Actual-test.wantActuals[i]) > 1e-9 {
+					t.Errorf("step %v Actual got %v want %v", i, step.Actual, test.wantActuals[i])
+				}
+				if step.InTolerance != test.wantInTols[i] {
+					t.Errorf("step %v InTolerance got %v want %v", i, step.InTolerance, test.wantInTols[i])
+				}
+				if math.Abs(step.Tare-test.wantTares[i]) > 1e-9 {
+					t.Errorf("step %v Tare got %v want %v", i, step.Tare, test.wantTares[i])
+				}
+			}
+		})
+	}
+}
+
+func TestBatchEngineErrors(t *testing.T) {
+	batchEngine := &BatchEngine{}
+	if batchEngine.Start(testStart) == nil {
+		t.Error("Start with no ingredients expected error")
+	}
+	if batchEngine.Next(testStart) == nil {
+		t.Error("Next before Start expected error")
+	}
+
+	batchEngine.Recipe = Recipe{Ingredients: []Ingredient{{Name: "water", Target: 10}}}
+	err := batchEngine.Start(testStart)
+	if err != nil {
+		t.Fatalf("Start error: %v", err)
+	}
+	if batchEngine.Next(testStart) == nil {
+		t.Error("Next before tare expected error")
+	}
+}
@@ -0,0 +1,242 @@
+package hx711
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// SpeedSource gives the belt speed in length per second
+type SpeedSource interface {
+	Speed() (float64, error)
+}
+
+// SpeedFunc is a SpeedSource using a function, such as one reading a drive controller
+type SpeedFunc func() (float64, error)
+
+// Speed calls the function
+func (speedFunc SpeedFunc) Speed() (float64, error) {
+	return speedFunc()
+}
+
+// distanceSource is a SpeedSource that also counts distance, which is used instead of integrating speed
+type distanceSource interface {
+	Distance() float64
+}
+
+// PulseCounter counts pulses from a belt speed sensor, such as a tachometer wheel or a proximity sensor on a pulley.
+// Create one with NewGPIOPulseCounter or pass pulses to Pulse.
+type PulseCounter struct {
+	// DistancePerPulse is the belt travel for each pulse
+	DistancePerPulse float64
+
+	mutex      sync.Mutex
+	count      uint64
+	lastPulse  time.Time
+	lastPeriod time.Duration
+	stop       chan struct{}
+	stopped    chan struct{}
+}
+
+// Pulse counts a pulse at pulseTime
+func (pulseCounter *PulseCounter) Pulse(pulseTime time.Time) {
+	pulseCounter.mutex.Lock()
+	defer pulseCounter.mutex.Unlock()
+
+	if pulseCounter.count > 0 {
+		pulseCounter.lastPeriod = pulseTime.Sub(pulseCounter.lastPulse)
+	}
+	pulseCounter.count++
+	pulseCounter.lastPulse = pulseTime
+}
+
+// Count returns the number of pulses counted
+func (pulseCounter *PulseCounter) Count() uint64 {
+	pulseCounter.mutex.Lock()
+	defer pulseCounter.mutex.Unlock()
+	return pulseCounter.count
+}
+
+// Distance returns the belt travel of the pulses counted
+func (pulseCounter *PulseCounter) Distance() float64 {
+	return float64(pulseCounter.Count()) * pulseCounter.DistancePerPulse
+}
+
+// Speed returns the belt speed from the time between the last two pulses.
+// If it has been longer than that since the last pulse, the speed falls off so a stopped belt reads zero.
+func (pulseCounter *PulseCounter) Speed() (float64, error) {
+	pulseCounter.mutex.Lock()
+	defer pulseCounter.mutex.Unlock()
+
+	if pulseCounter.lastPeriod <= 0 {
+		return 0, nil
+	}
+	period := pulseCounter.lastPeriod
+	sinceLast := time.Since(pulseCounter.lastPulse)
+	if sinceLast > period {
+		period = sinceLast
+	}
+	return pulseCounter.DistancePerPulse / period.Seconds(), nil
+}
+
+// Close stops counting GPIO pulses
+func (pulseCounter *PulseCounter) Close() error {
+	if pulseCounter.stop == nil {
+		return nil
+	}
+	close(pulseCounter.stop)
+	<-pulseCounter.stopped
+	pulseCounter.stop = nil
+	return nil
+}
+
+// BeltStatus is the state of a BeltScale after a reading
+type BeltStatus struct {
+	Time time.Time
+	// Load is the load per unit length on the belt
+	Load float64
+	// Speed is the belt speed in length per second
+	Speed float64
+	// Rate is the mass flow rate per second
+	Rate float64
+	// Total is the mass conveyed since the last ResetTotal
+	Total float64
+	// Distance is the belt travel since the last ResetTotal
+	Distance float64
+	// Zeroing is true while zero calibrating
+	Zeroing bool
+	// Zeroed is true for the reading that finished zero calibrating
+	Zeroed bool
+}
+
+// BeltScale is a conveyor belt scale. It combines the load on the weigh idlers with the belt speed
+// to get the mass flow rate and the total mass conveyed.
+// Pass it calibrated readings, such as from BackgroundReadings, with Update.
+type BeltScale struct {
+	// Speed is the belt speed source, if it is a PulseCounter the counted distance is used
+	Speed SpeedSource
+	// WeighLength is the length of belt carried by the scale, the load on the scale divided by it is the load per unit length
+	WeighLength float64
+	// BeltLength is the length of one belt revolution, used for zero calibration
+	BeltLength float64
+	// Zero is the load on the scale of the empty belt, set by zero calibration
+	Zero float64
+	// Callback if set is called with each status, from the goroutine calling Update
+	Callback func(BeltStatus)
+
+	mutex         sync.Mutex
+	status        BeltStatus
+	lastDistance  float64
+	zeroDistance  float64
+	zeroSum       float64
+	zeroRemaining float64
+}
+
+// StartZero starts zero calibrating the empty belt over revolutions belt revolutions.
+// Averaging over whole revolutions cancels out belt weight that varies along the belt.
+// The total is not added to while zero calibrating.
+func (beltScale *BeltScale) StartZero(revolutions int) error {
+	if revolutions < 1 {
+		return fmt.Errorf("revolutions less than 1")
+	}
+	if beltScale.BeltLength <= 0 {
+		return fmt.Errorf("BeltLength not set")
+	}
+
+	beltScale.mutex.Lock()
+	defer beltScale.mutex.Unlock()
+
+	beltScale.zeroDistance = 0
+	beltScale.zeroSum = 0
+	beltScale.zeroRemaining = float64(revolutions) * beltScale.BeltLength
+	beltScale.status.Zeroing = true
+	return nil
+}
+
+// Zeroing returns true while zero calibrating
+func (beltScale *BeltScale) Zeroing() bool {
+	beltScale.mutex.Lock()
+	defer beltScale.mutex.Unlock()
+	return beltScale.status.Zeroing
+}
+
+// Status returns the state after the last reading
+func (beltScale *BeltScale) Status() BeltStatus {
+	beltScale.mutex.Lock()
+	defer beltScale.mutex.Unlock()
+	return beltScale.status
+}
+
+// ResetTotal sets the total and distance to zero
+func (beltScale *BeltScale) ResetTotal() {
+	beltScale.mutex.Lock()
+	defer beltScale.mutex.Unlock()
+	beltScale.status.Total = 0
+	beltScale.status.Distance = 0
+}
+
+// Update adds a calibrated reading of the load on the scale and returns the new state.
+// Readings need to be passed in time order.
+func (beltScale *BeltScale) Update(reading Reading) (BeltStatus, error) {
+	if beltScale.Speed == nil {
+		return BeltStatus{}, fmt.Errorf("Speed not set")
+	}
+	if beltScale.WeighLength <= 0 {
+		return BeltStatus{}, fmt.Errorf("WeighLength not set")
+	}
+
+	speed, err := beltScale.Speed.Speed()
+	if err != nil {
+		return BeltStatus{}, fmt.Errorf("Speed error: %v", err)
+	}
+
+	beltScale.mutex.Lock()
+
+	// belt travel since the last reading
+	var distance float64
+	counter, isCounter := beltScale.Speed.(distanceSource)
+	if isCounter {
+		current := counter.Distance()
+		if !beltScale.status.Time.IsZero() {
+			distance = current - beltScale.lastDistance
+		}
+		beltScale.lastDistance = current
+	} else if !beltScale.status.Time.IsZero() {
+		distance = speed * reading.Time.Sub(beltScale.status.Time).Seconds()
+	}
+	if distance < 0 {
+		distance = 0
+	}
+
+	status := &beltScale.status
+	status.Time = reading.Time
+	status.Speed = speed
+	status.Zeroed = false
+
+	if status.Zeroing {
+		// distance weighted average of the empty belt load
+		beltScale.zeroSum += reading.Value * distance
+		beltScale.zeroDistance += distance
+		beltScale.zeroRemaining -= distance
+		if beltScale.zeroRemaining <= 0 && beltScale.zeroDistance > 0 {
+			beltScale.Zero = beltScale.zeroSum / beltScale.zeroDistance
+			status.Zeroing = false
+			status.Zeroed = true
+		}
+		status.Load = (reading.Value - beltScale.Zero) / beltScale.WeighLength
+		status.Rate = 0
+	} else {
+		status.Load = (reading.Value - beltScale.Zero) / beltScale.WeighLength
+		status.Rate = status.Load * speed
+		status.Total += status.Load * distance
+		status.Distance += distance
+	}
+
+	result := *status
+	beltScale.mutex.Unlock()
+
+	if beltScale.Callback != nil {
+		beltScale.Callback(result)
+	}
+	return result, nil
+}
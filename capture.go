@@ -0,0 +1,176 @@
+package hx711
+
+import (
+	"fmt"
+	"time"
+)
+
+// ErrCaptureStopped is returned by RunCapture when stopped before the capture was done
+var ErrCaptureStopped = fmt.Errorf("capture stopped")
+
+// TriggerEdge is the direction that triggers a Capture
+type TriggerEdge int
+
+const (
+	// TriggerRising triggers on rising values
+	TriggerRising TriggerEdge = iota
+	// TriggerFalling triggers on falling values
+	TriggerFalling
+	// TriggerEither triggers on rising or falling values
+	TriggerEither
+)
+
+// Sample is a raw reading, its calibrated value, and the time it was read
+type Sample struct {
+	Time  time.Time
+	Raw   int
+	Value float64
+}
+
+// CaptureResult is the samples saved by a Capture
+type CaptureResult struct {
+	Samples []Sample
+	// TriggerIndex is the index in Samples of the sample that triggered
+	TriggerIndex int
+}
+
+// Capture keeps a ring buffer of samples and, when triggered,
+// saves PreTrigger samples before the trigger and PostTrigger samples after it.
+// Triggers when the calibrated value crosses Level, or if Slope is set,
+// when the change per second between samples is more than Slope, in the direction of Edge.
+// Use with RunCapture, or call Arm then pass it samples with Update.
+type Capture struct {
+	// PreTrigger is the number of samples saved before the trigger, less than 0 is treated as 0
+	PreTrigger int
+	// PostTrigger is the number of samples saved after the trigger, less than 0 is treated as 0
+	PostTrigger int
+	Level       float64
+	// Slope if more than 0 triggers on change per second instead of Level
+	Slope float64
+	Edge  TriggerEdge
+
+	ring      []Sample
+	next      int
+	filled    bool
+	last      Sample
+	hasLast   bool
+	triggered bool
+	result    CaptureResult
+}
+
+// TriggerTime returns the time of the sample that triggered
+func (captureResult CaptureResult) TriggerTime() time.Time {
+	if captureResult.TriggerIndex >= len(captureResult.Samples) {
+		return time.Time{}
+	}
+	return captureResult.Samples[captureResult.TriggerIndex].Time
+}
+
+// validate returns an error if PreTrigger or PostTrigger is less than 0
+func (capture *Capture) validate() error {
+	if capture.PreTrigger < 0 {
+		return fmt.Errorf("PreTrigger is less than 0")
+	}
+	if capture.PostTrigger < 0 {
+		return fmt.Errorf("PostTrigger is less than 0")
+	}
+	return nil
+}
+
+// Arm clears the samples and waits for the next trigger
+func (capture *Capture) Arm() {
+	preTrigger := capture.PreTrigger
+	if preTrigger < 0 {
+		preTrigger = 0
+	}
+	if cap(capture.ring) < preTrigger {
+		capture.ring = make([]Sample, preTrigger)
+	}
+	capture.ring = capture.ring[:preTrigger]
+	capture.next = 0
+	capture.filled = false
+	capture.hasLast = false
+	capture.triggered = false
+	capture.result = CaptureResult{}
+}
+
+// Triggered returns true if the capture has triggered and is saving post trigger samples
+func (capture *Capture) Triggered() bool {
+	return capture.triggered
+}
+
+// isTrigger returns true if going from the last sample to sample is a trigger
+func (capture *Capture) isTrigger(sample Sample) bool {
+	if !capture.hasLast {
+		return false
+	}
+
+	var rising bool
+	var falling bool
+	if capture.Slope > 0 {
+		seconds := sample.Time.Sub(capture.last.Time).Seconds()
+		if seconds <= 0 {
+			return false
+		}
+		rate := (sample.Value - capture.last.Value) / seconds
+		rising = rate >= capture.Slope
+		falling = rate <= -capture.Slope
+	} else {
+		rising = capture.last.Value < capture.Level && sample.Value >= capture.Level
+		falling = capture.last.Value > capture.Level && sample.Value <= capture.Level
+	}
+
+	switch capture.Edge {
+	case TriggerRising:
+		return rising
+	case TriggerFalling:
+		return falling
+	}
+	return rising || falling
+}
+
+// Update adds a sample. Samples need to be passed in time order.
+// Returns the result and true when the capture is done.
+func (capture *Capture) Update(sample Sample) (CaptureResult, bool) {
+	if capture.triggered {
+		capture.result.Samples = append(capture.result.Samples, sample)
+		if len(capture.result.Samples) > capture.result.TriggerIndex+capture.PostTrigger {
+			capture.triggered = false
+			return capture.result, true
+		}
+		return CaptureResult{}, false
+	}
+
+	if capture.isTrigger(sample) {
+		capture.triggered = true
+		postTrigger := capture.PostTrigger
+		if postTrigger < 0 {
+			postTrigger = 0
+		}
+		capture.result.Samples = make([]Sample, 0, len(capture.ring)+1+postTrigger)
+		if capture.filled {
+			capture.result.Samples = append(capture.result.Samples, capture.ring[capture.next:]...)
+		}
+		capture.result.Samples = append(capture.result.Samples, capture.ring[:capture.next]...)
+		capture.result.TriggerIndex = len(capture.result.Samples)
+		capture.result.Samples = append(capture.result.Samples, sample)
+		if capture.PostTrigger < 1 {
+			capture.triggered = false
+			return capture.result, true
+		}
+		return CaptureResult{}, false
+	}
+
+	capture.last = sample
+	capture.hasLast = true
+	if len(capture.ring) > 0 {
+		capture.ring[capture.next] = sample
+		capture.next++
+		if capture.next >= len(capture.ring) {
+			capture.next = 0
+			capture.filled = true
+		}
+	}
+
+	return CaptureResult{}, false
+}
@@ -0,0 +1,58 @@
+package hx711
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCapture(t *testing.T) {
+	// values rise through the level of 5 at index 5
+	values := []float64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+
+	tests := []struct {
+		name        string
+		preTrigger  int
+		postTrigger int
+		want        []float64
+		wantTrigger int
+	}{
+		{name: "pre and post", preTrigger: 2, postTrigger: 3, want: []float64{3, 4, 5, 6, 7, 8}, wantTrigger: 2},
+		{name: "more pre than samples", preTrigger: 8, postTrigger: 1, want: []float64{0, 1, 2, 3, 4, 5, 6}, wantTrigger: 5},
+		{name: "no pre or post", want: []float64{5}},
+		{name: "negative pre and post", preTrigger: -1, postTrigger: -2, want: []float64{5}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			capture := &Capture{PreTrigger: test.preTrigger, PostTrigger: test.postTrigger, Level: 5, Edge: TriggerRising}
+			capture.Arm()
+
+			var result CaptureResult
+			var done bool
+			for i, value := range values {
+				result, done = capture.Update(Sample{Time: testStart.Add(time.Duration(i) * time.Second), Value: value})
+				if done {
+					break
+				}
+			}
+
+			if !done {
+				t.Fatal("capture not done")
+			}
+			if result.TriggerIndex != test.wantTrigger {
+				t.Errorf("TriggerIndex got %v want %v", result.TriggerIndex, test.wantTrigger)
+			}
+			if len(result.Samples) != len(test.want) {
+				t.Fatalf("Samples got %v want %v", len(result.Samples), len(test.want))
+			}
+			for i := range result.Samples {
+				if result.Samples[i].Value != test.want[i] {
+					t.Errorf("sample %v got %v want %v", i, result.Samples[i].Value, test.want[i])
+				}
+			}
+			if !result.TriggerTime().Equal(testStart.Add(5 * time.Second)) {
+				t.Errorf("TriggerTime got %v", result.TriggerTime())
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package hx711
+
+import (
+	"math"
+	"time"
+)
+
+// CheckClass is the classification of a check weighing
+type CheckClass int
+
+const (
+	// CheckUnder is below Target minus UnderTolerance
+	CheckUnder CheckClass = iota
+	// CheckAccept is within the tolerances of Target
+	CheckAccept
+	// CheckOver is above Target plus OverTolerance
+	CheckOver
+)
+
+// CheckResult is the result of a check weighing
+type CheckResult struct {
+	Class  CheckClass
+	Weight float64
+	// Deviation is Weight minus Target
+	Deviation float64
+	// Reject is true when the item should be rejected, when Class is not CheckAccept
+	Reject bool
+	Time   time.Time
+}
+
+// CheckStats is the running counts and statistics of check weighings
+type CheckStats struct {
+	Count  int
+	Under  int
+	Accept int
+	Over   int
+	Mean   float64
+	StdDev float64
+	Min    float64
+	Max    float64
+}
+
+// Checkweigher classifies items as under, accept, or over a target weight.
+// Each item is weighed once, on the first stable reading after the scale was empty.
+// Pass it readings, such as from BackgroundReadings, with Update.
+type Checkweigher struct {
+	Target float64
+	// UnderTolerance is how far below Target is still accepted
+	UnderTolerance float64
+	// OverTolerance is how far above Target is still accepted
+	OverTolerance float64
+	// EmptyThreshold is the weight below which the scale is empty and ready for the next item
+	EmptyThreshold float64
+	// Tare is the container weight subtracted from stable readings before they are classified
+	Tare float64
+	// Stabilizer decides when the readings have settled
+	Stabilizer Stabilizer
+	// Callback if set is called for each result, from the goroutine calling Update
+	Callback func(CheckResult)
+	// Events if set is sent each result, results are dropped if the channel is full
+	Events chan CheckResult
+
+	weighed bool
+	stats   CheckStats
+	sumSq   float64
+}
+
+// String returns the name of the check class
+func (checkClass CheckClass) String() string {
+	switch checkClass {
+	case CheckUnder:
+		return "under"
+	case CheckAccept:
+		return "accept"
+	case CheckOver:
+		return "over"
+	}
+	return "unknown"
+}
+
+// Classify returns the class of weight
+func (checkweigher *Checkweigher) Classify(weight float64) CheckClass {
+	if weight < checkweigher.Target-checkweigher.UnderTolerance {
+		return CheckUnder
+	}
+	if weight > checkweigher.Target+checkweigher.OverTolerance {
+		return CheckOver
+	}
+	return CheckAccept
+}
+
+// Update adds a reading. Readings need to be passed in time order.
+// Returns the result and true when an item has been weighed.
+func (checkweigher *Checkweigher) Update(reading Reading) (CheckResult, bool) {
+	if reading.Value < checkweigher.EmptyThreshold {
+		checkweigher.weighed = false
+		checkweigher.Stabilizer.Reset()
+		return CheckResult{}, false
+	}
+	if checkweigher.weighed {
+		return CheckResult{}, false
+	}
+
+	weight, stable := checkweigher.Stabilizer.Update(reading)
+	if !stable {
+		return CheckResult{}, false
+	}
+	checkweigher.weighed = true
+	weight -= checkweigher.Tare
+
+	class := checkweigher.Classify(weight)
+	result := CheckResult{
+		Class:     class,
+		Weight:    weight,
+		Deviation: weight - checkweigher.Target,
+		Reject:    class != CheckAccept,
+		Time:      reading.Time,
+	}
+	checkweigher.addStats(result)
+
+	if checkweigher.Callback != nil {
+		checkweigher.Callback(result)
+	}
+	if checkweigher.Events != nil {
+		select {
+		case checkweigher.Events <- result:
+		default:
+		}
+	}
+
+	return result, true
+}
+
+// addStats adds result to the running statistics
+func (checkweigher *Checkweigher) addStats(result CheckResult) {
+	stats := &checkweigher.stats
+	switch result.Class {
+	case CheckUnder:
+		stats.Under++
+	case CheckAccept:
+		stats.Accept++
+	case CheckOver:
+		stats.Over++
+	}
+
+	if stats.Count == 0 || result.Weight < stats.Min {
+		stats.Min = result.Weight
+	}
+	if stats.Count == 0 || result.Weight > stats.Max {
+		stats.Max = result.Weight
+	}
+
+	// Welford's online mean and variance
+	stats.Count++
+	delta := result.Weight - stats.Mean
+	stats.Mean += delta / float64(stats.Count)
+	checkweigher.sumSq += delta * (result.Weight - stats.Mean)
+	if stats.Count > 1 {
+		stats.StdDev = math.Sqrt(checkweigher.sumSq / float64(stats.Count-1))
+	}
+}
+
+// Stats returns the running counts and statistics
+func (checkweigher *Checkweigher) Stats() CheckStats {
+	return checkweigher.stats
+}
+
+// ResetStats clears the running counts and statistics
+func (checkweigher *Checkweigher) ResetStats() {
+	checkweigher.stats = CheckStats{}
+	checkweigher.sumSq = 0
+}
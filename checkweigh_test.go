@@ -0,0 +1,120 @@
+package hx711
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestCheckweigherClassify(t *testing.T) {
+	checkweigher := &Checkweigher{Target: 100, UnderTolerance: 2, OverTolerance: 3}
+
+	tests := []struct {
+		weight float64
+		want   CheckClass
+	}{
+		{weight: 97.9, want: CheckUnder},
+		{weight: 98, want: CheckAccept},
+		{weight: 100, want: CheckAccept},
+		{weight: 103, want: CheckAccept},
+		{weight: 103.1, want: CheckOver},
+	}
+
+	for _, test := range tests {
+		got := checkweigher.Classify(test.weight)
+		if got != test.want {
+			t.Errorf("Classify %v got %v want %v", test.weight, got, test.want)
+		}
+	}
+}
+
+func TestCheckweigherUpdate(t *testing.T) {
+	tests := []struct {
+		name        string
+		tare        float64
+		items       []float64
+		wantClasses []CheckClass
+		wantStats   CheckStats
+	}{
+		{
+			name:        "no tare",
+			items:       []float64{97, 100, 104, 102.5},
+			wantClasses: []CheckClass{CheckUnder, CheckAccept, CheckOver, CheckAccept},
+			wantStats: CheckStats{
+				Count: 4, Under: 1, Accept: 2, Over: 1,
+				Mean: 100.875, StdDev: 3.0653, Min: 97, Max: 104,
+			},
+		},
+		{
+			name:        "with tare",
+			tare:        20,
+			items:       []float64{120, 117},
+			wantClasses: []CheckClass{CheckAccept, CheckUnder},
+			wantStats: CheckStats{
+				Count: 2, Under: 1, Accept: 1,
+				Mean: 98.5, StdDev: 2.1213, Min: 97, Max: 100,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var events []CheckResult
+			checkweigher := &Checkweigher{
+				Target:         100,
+				UnderTolerance: 2,
+				OverTolerance:  3,
+				EmptyThreshold: 10,
+				Tare:           test.tare,
+				Stabilizer:     Stabilizer{Tolerance: 0.5, Duration: 300 * time.Millisecond},
+				Callback: func(result CheckResult) {
+					events = append(events, result)
+				},
+			}
+
+			stream := &readingStream{interval: 100 * time.Millisecond}
+			for _, item := range test.items {
+				stream.hold(0, 5).ramp(item, 3).hold(item, 10)
+			}
+			stream.hold(0, 5)
+
+			var results []CheckResult
+			for _, reading := range stream.readings {
+				result, ok := checkweigher.Update(reading)
+				if ok {
+					results = append(results, result)
+				}
+			}
+
+			if len(results) != len(test.wantClasses) || len(events) != len(test.wantClasses) {
+				t.Fatalf("results got %v events got %v want %v", len(results), len(events), len(test.wantClasses))
+			}
+			for i, result := range results {
+				if result.Class != test.wantClasses[i] {
+					t.Errorf("item %v Class got %v want %v", i, result.Class, test.wantClasses[i])
+				}
+				if result.Reject != (test.wantClasses[i] != CheckAccept) {
+					t.Errorf("item %v Reject got %v", i, result.Reject)
+				}
+				if math.Abs(result.Weight-(test.items[i]-test.tare)) > 1e-9 {
+					t.Errorf("item %v Weight got %v want %v", i, result.Weight, test.items[i]-test.tare)
+				}
+			}
+
+			stats := checkweigher.Stats()
+			if stats.Count != test.wantStats.Count || stats.Under != test.wantStats.Under ||
+				stats.Accept != test.wantStats.Accept || stats.Over != test.wantStats.Over {
+				t.Errorf("counts got %+v want %+v", stats, test.wantStats)
+			}
+			if math.Abs(stats.Mean-test.wantStats.Mean) > 1e-9 || math.Abs(stats.StdDev-test.wantStats.StdDev) > 1e-4 ||
+				stats.Min != test.wantStats.Min || stats.Max != test.wantStats.Max {
+				t.Errorf("stats got %+v want %+v", stats, test.wantStats)
+			}
+
+			checkweigher.ResetStats()
+			if checkweigher.Stats().Count != 0 {
+				t.Error("ResetStats did not clear Count")
+			}
+		})
+	}
+}
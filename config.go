@@ -0,0 +1,16 @@
+package hx711
+
+// Config is the configuration used by NewHx711WithConfig
+type Config struct {
+	// ClockPinName is the name of the pin connected to the chip PD_SCK pin
+	ClockPinName string
+	// DataPinName is the name of the pin connected to the chip DOUT pin
+	DataPinName string
+	// RatePinName is optional, the name of the pin connected to the chip RATE pin.
+	// If set, the output data rate can be changed with SetRate
+	RatePinName string
+	// SamplesPerSecond is the output data rate, 10 or 80, default is 10, other values are an error.
+	// If RatePinName is set the RATE pin is set to match,
+	// otherwise it should match how the RATE pin is wired.
+	SamplesPerSecond int
+}
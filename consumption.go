@@ -0,0 +1,267 @@
+package hx711
+
+import (
+	"time"
+)
+
+const (
+	// defaultConsumptionWindow is the default time span of readings fit for the consumption rate
+	defaultConsumptionWindow = time.Minute
+	// minConsumptionReadings is the fewest readings fit for the consumption rate
+	minConsumptionReadings = 3
+	// minConsumptionSpan is the least fraction of the window the readings need to span for a rate
+	minConsumptionSpan = 0.5
+)
+
+// ConsumptionEventType is the type of a ConsumptionEvent
+type ConsumptionEventType int
+
+const (
+	// ConsumptionRefill is when the container was refilled, Value is the weight added
+	ConsumptionRefill ConsumptionEventType = iota
+	// ConsumptionDayTotal is at the end of each day, Value is the amount used that day
+	ConsumptionDayTotal
+	// ConsumptionLow is when the projected time until empty drops below LowTime, Value is the weight left
+	ConsumptionLow
+)
+
+// ConsumptionEvent is sent by a ConsumptionTracker
+type ConsumptionEvent struct {
+	Type  ConsumptionEventType
+	Value float64
+	Time  time.Time
+	// Day is the start of the day of a ConsumptionDayTotal
+	Day time.Time
+}
+
+// ConsumptionStatus is the state of a ConsumptionTracker after a reading
+type ConsumptionStatus struct {
+	Time time.Time
+	// Weight is the fitted weight in the container
+	Weight float64
+	// Rate is the amount used per second, negative if the weight is going up
+	Rate float64
+	// DayTotal is the amount used so far today, refills are not counted
+	DayTotal float64
+	// Remaining is the projected time until empty, zero if the weight is not going down
+	Remaining time.Duration
+	// EmptyAt is the projected time of empty, zero if the weight is not going down
+	EmptyAt time.Time
+	// Refilling is true while the container is being refilled
+	Refilling bool
+}
+
+// ConsumptionTracker tracks how fast the contents of a container, such as a keg or feed hopper, are used.
+// The rate is a robust fit over the last Window of readings, so outliers and noise have little effect.
+// Refills are detected and left out of the rate and the daily totals.
+// Pass it calibrated readings, such as from BackgroundReadings, with Update.
+type ConsumptionTracker struct {
+	// Window is the time span of readings fit for the rate, default is 1 minute
+	Window time.Duration
+	// RefillThreshold is how far above the fitted weight a reading needs to be to start a refill,
+	// and the least weight added for a refill event
+	RefillThreshold float64
+	// Stabilizer decides when a refill has finished
+	Stabilizer Stabilizer
+	// EmptyWeight is the weight when the container is empty
+	EmptyWeight float64
+	// LowTime if set sends a ConsumptionLow event when the projected time until empty drops below it
+	LowTime time.Duration
+	// Location is the time zone for the start of a day, default is time.Local
+	Location *time.Location
+	// Callback if set is called for each event, from the goroutine calling Update
+	Callback func(ConsumptionEvent)
+	// Events if set is sent each event, events are dropped if the channel is full
+	Events chan ConsumptionEvent
+
+	window      []Reading
+	status      ConsumptionStatus
+	hasRate     bool
+	day         time.Time
+	refillStart float64
+	lowSent     bool
+}
+
+// String returns the name of the consumption event type
+func (consumptionEventType ConsumptionEventType) String() string {
+	switch consumptionEventType {
+	case ConsumptionRefill:
+		return "refill"
+	case ConsumptionDayTotal:
+		return "day total"
+	case ConsumptionLow:
+		return "low"
+	}
+	return "unknown"
+}
+
+// Status returns the state after the last reading
+func (consumptionTracker *ConsumptionTracker) Status() ConsumptionStatus {
+	return consumptionTracker.status
+}
+
+// Update adds a reading. Readings need to be passed in time order.
+// Returns the state and true if there are enough readings for a rate.
+func (consumptionTracker *ConsumptionTracker) Update(reading Reading) (ConsumptionStatus, bool) {
+	status := &consumptionTracker.status
+
+	consumptionTracker.checkDay(reading.Time)
+
+	if status.Refilling {
+		consumptionTracker.updateRefill(reading)
+		return *status, false
+	}
+
+	if consumptionTracker.hasRate && consumptionTracker.RefillThreshold > 0 {
+		predicted := status.Weight - status.Rate*reading.Time.Sub(status.Time).Seconds()
+		if reading.Value-predicted > consumptionTracker.RefillThreshold {
+			status.Refilling = true
+			consumptionTracker.refillStart = predicted
+			consumptionTracker.Stabilizer.Reset()
+			consumptionTracker.Stabilizer.Update(reading)
+			return *status, false
+		}
+	}
+
+	consumptionTracker.addReading(reading)
+	return *status, consumptionTracker.hasRate
+}
+
+// addReading adds a reading to the window and fits the rate
+func (consumptionTracker *ConsumptionTracker) addReading(reading Reading) {
+	status := &consumptionTracker.status
+
+	window := consumptionTracker.Window
+	if window <= 0 {
+		window = defaultConsumptionWindow
+	}
+	consumptionTracker.window = append(consumptionTracker.window, reading)
+	start := reading.Time.Add(-window)
+	drop := 0
+	for drop < len(consumptionTracker.window) && consumptionTracker.window[drop].Time.Before(start) {
+		drop++
+	}
+	if drop > 0 {
+		consumptionTracker.window = append(consumptionTracker.window[:0], consumptionTracker.window[drop:]...)
+	}
+
+	span := reading.Time.Sub(consumptionTracker.window[0].Time)
+	if len(consumptionTracker.window) < minConsumptionReadings || span.Seconds() < minConsumptionSpan*window.Seconds() {
+		status.Time = reading.Time
+		status.Weight = reading.Value
+		return
+	}
+
+	xs := make([]float64, len(consumptionTracker.window))
+	ys := make([]float64, len(consumptionTracker.window))
+	for i := range consumptionTracker.window {
+		xs[i] = consumptionTracker.window[i].Time.Sub(reading.Time).Seconds()
+		ys[i] = consumptionTracker.window[i].Value
+	}
+	intercept, slope, err := robustLinearFit(xs, ys)
+	if err != nil {
+		return
+	}
+
+	// the amount used since the last reading, or over the readings for the first rate,
+	// from the rate so noise does not add up
+	if consumptionTracker.hasRate {
+		status.DayTotal += -slope * reading.Time.Sub(status.Time).Seconds()
+	} else {
+		status.DayTotal += -slope * span.Seconds()
+	}
+
+	status.Time = reading.Time
+	status.Weight = intercept
+	status.Rate = -slope
+	consumptionTracker.hasRate = true
+
+	status.Remaining = 0
+	status.EmptyAt = time.Time{}
+	if status.Rate > 0 && status.Weight > consumptionTracker.EmptyWeight {
+		status.Remaining = time.Duration((status.Weight - consumptionTracker.EmptyWeight) / status.Rate * float64(time.Second))
+		status.EmptyAt = reading.Time.Add(status.Remaining)
+	}
+
+	if consumptionTracker.LowTime > 0 && !consumptionTracker.lowSent &&
+		status.Remaining > 0 && status.Remaining < consumptionTracker.LowTime {
+		consumptionTracker.lowSent = true
+		consumptionTracker.sendEvent(ConsumptionEvent{
+			Type:  ConsumptionLow,
+			Value: status.Weight - consumptionTracker.EmptyWeight,
+			Time:  reading.Time,
+		})
+	}
+}
+
+// updateRefill waits for the weight to settle after a refill starts
+func (consumptionTracker *ConsumptionTracker) updateRefill(reading Reading) {
+	status := &consumptionTracker.status
+
+	settled, stable := consumptionTracker.Stabilizer.Update(reading)
+	if !stable {
+		return
+	}
+	status.Refilling = false
+
+	added := settled - consumptionTracker.refillStart
+	if added < consumptionTracker.RefillThreshold {
+		// a bump, not a refill, keep fitting the same readings
+		return
+	}
+
+	// start the fit again from the settled weight
+	consumptionTracker.window = consumptionTracker.window[:0]
+	consumptionTracker.hasRate = false
+	consumptionTracker.lowSent = false
+	status.Rate = 0
+	status.Remaining = 0
+	status.EmptyAt = time.Time{}
+	consumptionTracker.addReading(reading)
+
+	consumptionTracker.sendEvent(ConsumptionEvent{
+		Type:  ConsumptionRefill,
+		Value: added,
+		Time:  reading.Time,
+	})
+}
+
+// checkDay sends the day total and starts a new day when readingTime is in a new day
+func (consumptionTracker *ConsumptionTracker) checkDay(readingTime time.Time) {
+	location := consumptionTracker.Location
+	if location == nil {
+		location = time.Local
+	}
+	localTime := readingTime.In(location)
+	day := time.Date(localTime.Year(), localTime.Month(), localTime.Day(), 0, 0, 0, 0, location)
+
+	if consumptionTracker.day.IsZero() {
+		consumptionTracker.day = day
+		return
+	}
+	if day.Equal(consumptionTracker.day) {
+		return
+	}
+
+	consumptionTracker.sendEvent(ConsumptionEvent{
+		Type:  ConsumptionDayTotal,
+		Value: consumptionTracker.status.DayTotal,
+		Time:  readingTime,
+		Day:   consumptionTracker.day,
+	})
+	consumptionTracker.day = day
+	consumptionTracker.status.DayTotal = 0
+}
+
+// sendEvent calls Callback and sends to Events if they are set
+func (consumptionTracker *ConsumptionTracker) sendEvent(event ConsumptionEvent) {
+	if consumptionTracker.Callback != nil {
+		consumptionTracker.Callback(event)
+	}
+	if consumptionTracker.Events != nil {
+		select {
+		case consumptionTracker.Events <- event:
+		default:
+		}
+	}
+}
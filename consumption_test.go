@@ -0,0 +1,160 @@
+package hx711
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// consumptionStream returns readings every 10 seconds for duration of a container starting at start
+// being used at rate per second, with refill added at refillAt and outlier added every outlierEvery readings
+func consumptionStream(startTime time.Time, duration time.Duration, start float64, rate float64,
+	refillAt time.Duration, refill float64, outlier float64, outlierEvery int) []Reading {
+	var readings []Reading
+	weight := start
+	interval := 10 * time.Second
+	for elapsed := time.Duration(0); elapsed <= duration; elapsed += interval {
+		if refill != 0 && elapsed == refillAt {
+			weight += refill
+		}
+		value := weight
+		if outlierEvery > 0 && len(readings)%outlierEvery == outlierEvery-1 {
+			value += outlier
+		}
+		readings = append(readings, Reading{Time: startTime.Add(elapsed), Value: value})
+		weight -= rate * interval.Seconds()
+	}
+	return readings
+}
+
+func TestConsumptionTracker(t *testing.T) {
+	tests := []struct {
+		name         string
+		readings     []Reading
+		wantRate     float64
+		wantWeight   float64
+		wantRefills  []float64
+		wantDayTotal float64
+	}{
+		{
+			name:         "steady",
+			readings:     consumptionStream(testStart, 10*time.Minute, 1000, 0.1, 0, 0, 0, 0),
+			wantRate:     0.1,
+			wantWeight:   940,
+			wantDayTotal: 60,
+		},
+		{
+			name:         "outliers",
+			readings:     consumptionStream(testStart, 10*time.Minute, 1000, 0.1, 0, 0, 80, 7),
+			wantRate:     0.1,
+			wantWeight:   940,
+			wantDayTotal: 60,
+		},
+		{
+			name:         "refill",
+			readings:     consumptionStream(testStart, 20*time.Minute, 1000, 0.1, 5*time.Minute, 500, 0, 0),
+			wantRate:     0.1,
+			wantWeight:   1380,
+			wantRefills:  []float64{500},
+			wantDayTotal: 120,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var refills []float64
+			consumptionTracker := &ConsumptionTracker{
+				RefillThreshold: 100,
+				Stabilizer:      Stabilizer{Tolerance: 5, Duration: 30 * time.Second},
+				Location:        time.UTC,
+				Callback: func(event ConsumptionEvent) {
+					if event.Type == ConsumptionRefill {
+						refills = append(refills, event.Value)
+					}
+				},
+			}
+
+			var status ConsumptionStatus
+			var ok bool
+			for _, reading := range test.readings {
+				status, ok = consumptionTracker.Update(reading)
+			}
+
+			if !ok {
+				t.Fatal("no rate")
+			}
+			if math.Abs(status.Rate-test.wantRate) > 1e-6 {
+				t.Errorf("Rate got %v want %v", status.Rate, test.wantRate)
+			}
+			if math.Abs(status.Weight-test.wantWeight) > 1e-6 {
+				t.Errorf("Weight got %v want %v", status.Weight, test.wantWeight)
+			}
+			wantRemaining := time.Duration(test.wantWeight / test.wantRate * float64(time.Second))
+			if math.Abs((status.Remaining - wantRemaining).Seconds()) > 1 {
+				t.Errorf("Remaining got %v want %v", status.Remaining, wantRemaining)
+			}
+			if len(refills) != len(test.wantRefills) {
+				t.Fatalf("refills got %v want %v", refills, test.wantRefills)
+			}
+			for i := range refills {
+				// the settled weight is averaged over the Stabilizer Duration while still being used
+				if math.Abs(refills[i]-test.wantRefills[i]) > 5 {
+					t.Errorf("refill %v got %v want %v", i, refills[i], test.wantRefills[i])
+				}
+			}
+			// time to get the first rate and to settle after a refill are not counted exactly
+			if math.Abs(status.DayTotal-test.wantDayTotal) > 5 {
+				t.Errorf("DayTotal got %v want %v", status.DayTotal, test.wantDayTotal)
+			}
+		})
+	}
+}
+
+func TestConsumptionTrackerEvents(t *testing.T) {
+	events := make(chan ConsumptionEvent, 10)
+	consumptionTracker := &ConsumptionTracker{
+		RefillThreshold: 100,
+		Stabilizer:      Stabilizer{Tolerance: 5, Duration: 30 * time.Second},
+		LowTime:         2 * time.Hour,
+		Location:        time.UTC,
+		Events:          events,
+	}
+
+	// 800 at 0.1 per second is empty in about 2.2 hours, so low after about 800 seconds
+	startTime := time.Date(2020, 1, 1, 23, 50, 0, 0, time.UTC)
+	for _, reading := range consumptionStream(startTime, 20*time.Minute, 800, 0.1, 0, 0, 0, 0) {
+		consumptionTracker.Update(reading)
+	}
+	close(events)
+
+	var low []ConsumptionEvent
+	var dayTotals []ConsumptionEvent
+	for event := range events {
+		switch event.Type {
+		case ConsumptionLow:
+			low = append(low, event)
+		case ConsumptionDayTotal:
+			dayTotals = append(dayTotals, event)
+		default:
+			t.Errorf("unexpected event %v", event.Type)
+		}
+	}
+
+	if len(low) != 1 {
+		t.Fatalf("low events got %v want 1", len(low))
+	}
+	if low[0].Value > 720 || low[0].Value < 710 {
+		t.Errorf("low Value got %v want about 720", low[0].Value)
+	}
+
+	if len(dayTotals) != 1 {
+		t.Fatalf("day total events got %v want 1", len(dayTotals))
+	}
+	wantDay := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	if !dayTotals[0].Day.Equal(wantDay) {
+		t.Errorf("Day got %v want %v", dayTotals[0].Day, wantDay)
+	}
+	if math.Abs(dayTotals[0].Value-60) > 1 {
+		t.Errorf("day total got %v want 60", dayTotals[0].Value)
+	}
+}
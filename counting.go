@@ -0,0 +1,155 @@
+package hx711
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	// countHighConfidence is the count uncertainty, in pieces, below which a count is high confidence
+	countHighConfidence = 0.1
+	// countLowConfidence is the count uncertainty, in pieces, below which a count is low confidence
+	countLowConfidence = 0.25
+	// countRefineTolerance is how close to a whole number of pieces a count needs to be to refine the piece weight
+	countRefineTolerance = 0.15
+)
+
+// CountConfidence is how much a piece count can be trusted
+type CountConfidence int
+
+const (
+	// CountConfidenceHigh is when the count is almost surely right
+	CountConfidenceHigh CountConfidence = iota
+	// CountConfidenceLow is when the count may be off by one
+	CountConfidenceLow
+	// CountConfidenceNone is when the piece weight is too close to the noise of the scale for the count to be trusted
+	CountConfidenceNone
+)
+
+// PieceCount is the result of counting pieces
+type PieceCount struct {
+	Pieces int
+	// Exact is the weight divided by the piece weight before rounding
+	Exact float64
+	// Uncertainty is the standard deviation of the count in pieces
+	Uncertainty float64
+	Confidence  CountConfidence
+	PieceWeight float64
+}
+
+// PieceCounter counts pieces by weight.
+// Set the piece weight by weighing reference pieces with SetReference or SampleReference.
+// Weights should be net, with the container tared.
+type PieceCounter struct {
+	// Stabilizer decides when readings are stable enough to count in Update
+	Stabilizer Stabilizer
+	// Noise is the standard deviation of the scale readings, used for Uncertainty and Confidence
+	Noise float64
+	// AutoRefine refines the piece weight as more pieces are added,
+	// when a stable count is high confidence and close to a whole number of pieces
+	AutoRefine bool
+	// Tare is the container weight subtracted from readings in Update and SampleReference
+	Tare float64
+
+	pieceWeight     float64
+	referencePieces int
+}
+
+// String returns the name of the count confidence
+func (countConfidence CountConfidence) String() string {
+	switch countConfidence {
+	case CountConfidenceHigh:
+		return "high"
+	case CountConfidenceLow:
+		return "low"
+	case CountConfidenceNone:
+		return "none"
+	}
+	return "unknown"
+}
+
+// SetReference sets the piece weight from the weight of a number of reference pieces
+func (pieceCounter *PieceCounter) SetReference(weight float64, pieces int) error {
+	if pieces < 1 {
+		return fmt.Errorf("pieces is less than 1")
+	}
+	if weight <= 0 {
+		return fmt.Errorf("weight is not more than 0")
+	}
+	pieceCounter.pieceWeight = weight / float64(pieces)
+	pieceCounter.referencePieces = pieces
+	return nil
+}
+
+// PieceWeight returns the average piece weight and the number of pieces it is based on
+func (pieceCounter *PieceCounter) PieceWeight() (float64, int) {
+	return pieceCounter.pieceWeight, pieceCounter.referencePieces
+}
+
+// Count returns the number of pieces in weight
+func (pieceCounter *PieceCounter) Count(weight float64) (PieceCount, error) {
+	if pieceCounter.referencePieces < 1 {
+		return PieceCount{}, fmt.Errorf("no reference set")
+	}
+
+	exact := weight / pieceCounter.pieceWeight
+	pieces := int(math.Round(exact))
+
+	// noise of this reading plus the piece weight error, from the reference noise, times the pieces
+	pieceWeightError := pieceCounter.Noise / float64(pieceCounter.referencePieces)
+	uncertainty := math.Sqrt(pieceCounter.Noise*pieceCounter.Noise+
+		exact*pieceWeightError*exact*pieceWeightError) / pieceCounter.pieceWeight
+
+	confidence := CountConfidenceNone
+	if uncertainty < countHighConfidence {
+		confidence = CountConfidenceHigh
+	} else if uncertainty < countLowConfidence {
+		confidence = CountConfidenceLow
+	}
+
+	return PieceCount{
+		Pieces:      pieces,
+		Exact:       exact,
+		Uncertainty: uncertainty,
+		Confidence:  confidence,
+		PieceWeight: pieceCounter.pieceWeight,
+	}, nil
+}
+
+// Update adds a reading. Readings need to be passed in time order.
+// Returns the count and true when the readings are stable.
+// If AutoRefine is set, the piece weight is refined with the count.
+func (pieceCounter *PieceCounter) Update(reading Reading) (PieceCount, bool, error) {
+	value, stable := pieceCounter.Stabilizer.Update(reading)
+	if !stable {
+		return PieceCount{}, false, nil
+	}
+	value -= pieceCounter.Tare
+
+	count, err := pieceCounter.Count(value)
+	if err != nil {
+		return PieceCount{}, false, err
+	}
+
+	if pieceCounter.AutoRefine && count.Confidence == CountConfidenceHigh &&
+		count.Pieces > pieceCounter.referencePieces &&
+		math.Abs(count.Exact-float64(count.Pieces)) < countRefineTolerance {
+		pieceCounter.pieceWeight = value / float64(count.Pieces)
+		pieceCounter.referencePieces = count.Pieces
+		count.PieceWeight = pieceCounter.pieceWeight
+	}
+
+	return count, true, nil
+}
+
+// SampleReference sets the piece weight of pieceCounter by weighing pieces reference pieces
+// with median of numReadings raw readings, adjusted like ReadDataMedian.
+// Do not call Reset before or Shutdown after.
+// Reset and Shutdown are called for you.
+func (hx711 *Hx711) SampleReference(pieceCounter *PieceCounter, numReadings int, pieces int) error {
+	weight, err := hx711.ReadDataMedian(numReadings)
+	if err != nil {
+		return err
+	}
+	return pieceCounter.SetReference(weight-pieceCounter.Tare, pieces)
+}
@@ -0,0 +1,237 @@
+package hx711
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// LoadCompensation corrects calibrated readings for load cell creep and hysteresis.
+// Set Hx711 LoadCompensation to use it.
+// Creep is modeled as first order, after a load change the reading drifts by
+// CreepCoefficient times the load change with a time constant of CreepTimeConstant.
+// Hysteresis is modeled as unloading readings being higher than loading readings by
+// HysteresisCoefficient times the peak load at half the peak load, and less nearer zero and the peak.
+type LoadCompensation struct {
+	// CreepCoefficient is the creep after a long hold as a fraction of the load
+	CreepCoefficient float64
+	// CreepTimeConstant is the time constant of the creep
+	CreepTimeConstant time.Duration
+	// HysteresisCoefficient is the difference between unloading and loading readings
+	// at half the peak load, as a fraction of the peak load
+	HysteresisCoefficient float64
+	// StepThreshold is the smallest change in load that changes the loading or unloading direction,
+	// and the load below which the scale is treated as empty. Should be above the noise of the readings.
+	StepThreshold float64
+
+	lastTime  time.Time
+	creep     float64
+	lastLoad  float64
+	peakLoad  float64
+	unloading bool
+}
+
+// compensateLoad corrects an adjusted reading at the requested gain for creep and hysteresis
+// if LoadCompensation is set
+func (hx711 *Hx711) compensateLoad(reading float64) float64 {
+	channel, _ := hx711.RequestedGain()
+	return hx711.compensateChannelLoad(reading, channel)
+}
+
+// compensateChannelLoad corrects an adjusted reading from channel for creep and hysteresis if LoadCompensation is set.
+// Channel B readings are not corrected, the compensation is for the load cell on channel A.
+func (hx711 *Hx711) compensateChannelLoad(reading float64, channel Channel) float64 {
+	if hx711.LoadCompensation == nil || channel != ChannelA {
+		return reading
+	}
+	return hx711.LoadCompensation.Compensate(reading, time.Now())
+}
+
+// LoadSample is a calibrated reading with the known load on the scale at that time,
+// for estimating LoadCompensation
+type LoadSample struct {
+	Time    time.Time
+	Reading float64
+	Load    float64
+}
+
+// Reset clears the creep and hysteresis history, such as after a tare
+func (loadCompensation *LoadCompensation) Reset() {
+	loadCompensation.lastTime = time.Time{}
+	loadCompensation.creep = 0
+	loadCompensation.lastLoad = 0
+	loadCompensation.peakLoad = 0
+	loadCompensation.unloading = false
+}
+
+// Compensate returns reading, taken at readingTime, corrected for creep and hysteresis.
+// Readings need to be passed in time order.
+func (loadCompensation *LoadCompensation) Compensate(reading float64, readingTime time.Time) float64 {
+	if !loadCompensation.lastTime.IsZero() && loadCompensation.CreepTimeConstant > 0 {
+		elapsed := readingTime.Sub(loadCompensation.lastTime).Seconds()
+		if elapsed > 0 {
+			target := loadCompensation.CreepCoefficient * (reading - loadCompensation.creep)
+			loadCompensation.creep += (target - loadCompensation.creep) *
+				(1 - math.Exp(-elapsed/loadCompensation.CreepTimeConstant.Seconds()))
+		}
+	}
+	loadCompensation.lastTime = readingTime
+	load := reading - loadCompensation.creep
+
+	if load < loadCompensation.StepThreshold {
+		// scale is empty so start a new loading cycle
+		loadCompensation.peakLoad = 0
+		loadCompensation.unloading = false
+	} else if load > loadCompensation.lastLoad+loadCompensation.StepThreshold {
+		loadCompensation.unloading = false
+	} else if load < loadCompensation.lastLoad-loadCompensation.StepThreshold {
+		loadCompensation.unloading = true
+	}
+	if !loadCompensation.unloading && load > loadCompensation.peakLoad {
+		loadCompensation.peakLoad = load
+	}
+	loadCompensation.lastLoad = load
+
+	hysteresis := loadCompensation.hysteresis(load)
+	if loadCompensation.unloading {
+		return load - hysteresis/2
+	}
+	return load + hysteresis/2
+}
+
+// hysteresis returns the difference between unloading and loading readings at load for the current peak load
+func (loadCompensation *LoadCompensation) hysteresis(load float64) float64 {
+	if loadCompensation.peakLoad <= 0 {
+		return 0
+	}
+	fraction := load / loadCompensation.peakLoad
+	if fraction < 0 || fraction > 1 {
+		return 0
+	}
+	return loadCompensation.HysteresisCoefficient * loadCompensation.peakLoad * 4 * fraction * (1 - fraction)
+}
+
+// EstimateLoadCompensation estimates a LoadCompensation from a recorded session of
+// loading with known weights, holding the load, and unloading.
+// The creep is estimated from the longest hold at a constant load.
+// The hysteresis is estimated from loads that were recorded both while loading and while unloading,
+// if there are none then HysteresisCoefficient is 0.
+// stepThreshold is used for the StepThreshold of the returned LoadCompensation.
+func EstimateLoadCompensation(samples []LoadSample, stepThreshold float64) (*LoadCompensation, error) {
+	loadCompensation := &LoadCompensation{StepThreshold: stepThreshold}
+
+	// find the longest hold at a constant non-zero load
+	var holdStart int
+	var holdEnd int
+	for i := 0; i < len(samples); {
+		j := i
+		for j+1 < len(samples) && samples[j+1].Load == samples[i].Load {
+			j++
+		}
+		if samples[i].Load != 0 && samples[j].Time.Sub(samples[i].Time) > samples[holdEnd].Time.Sub(samples[holdStart].Time) {
+			holdStart = i
+			holdEnd = j
+		}
+		i = j + 1
+	}
+	if holdEnd-holdStart < 4 {
+		return nil, fmt.Errorf("no hold with at least 5 samples")
+	}
+
+	// the hold needs to be several time constants long so the creep has settled by the end,
+	// creep is the drift of the error over the hold, using the average of the last tenth as the end.
+	// The error at the start of the hold is calibration error, not creep.
+	holdLoad := samples[holdStart].Load
+	startError := samples[holdStart].Reading - holdLoad
+	var endError float64
+	endCount := (holdEnd-holdStart+1)/10 + 1
+	for i := holdEnd - endCount + 1; i <= holdEnd; i++ {
+		endError += samples[i].Reading - holdLoad
+	}
+	endError /= float64(endCount)
+	creep := endError - startError
+	loadCompensation.CreepCoefficient = creep / holdLoad
+
+	// fit ln(1 - fraction of creep) = -time / time constant
+	if creep != 0 {
+		var times []float64
+		var logs []float64
+		for i := holdStart; i <= holdEnd; i++ {
+			fraction := (samples[i].Reading - holdLoad - startError) / creep
+			if fraction > 0.05 && fraction < 0.95 {
+				times = append(times, samples[i].Time.Sub(samples[holdStart].Time).Seconds())
+				logs = append(logs, math.Log(1-fraction))
+			}
+		}
+		_, slope, err := linearFit(times, logs)
+		if err != nil {
+			return nil, fmt.Errorf("creep time constant fit error: %v", err)
+		}
+		if slope >= 0 {
+			return nil, fmt.Errorf("creep is not settling over the hold")
+		}
+		loadCompensation.CreepTimeConstant = time.Duration(-float64(time.Second) / slope)
+	}
+
+	// remove creep from the readings before looking at hysteresis
+	creepOnly := &LoadCompensation{
+		CreepCoefficient:  loadCompensation.CreepCoefficient,
+		CreepTimeConstant: loadCompensation.CreepTimeConstant,
+		StepThreshold:     stepThreshold,
+	}
+	readings := make([]float64, len(samples))
+	peak := 0
+	for i := range samples {
+		readings[i] = creepOnly.Compensate(samples[i].Reading, samples[i].Time)
+		if samples[i].Load > samples[peak].Load {
+			peak = i
+		}
+	}
+	peakLoad := samples[peak].Load
+	if peakLoad <= 0 {
+		return loadCompensation, nil
+	}
+
+	// compare average readings at the same load before and after the peak
+	loading := make(map[float64][]float64)
+	unloading := make(map[float64][]float64)
+	for i := range samples {
+		load := samples[i].Load
+		if load <= 0 || load >= peakLoad {
+			continue
+		}
+		if i < peak {
+			loading[load] = append(loading[load], readings[i])
+		} else {
+			unloading[load] = append(unloading[load], readings[i])
+		}
+	}
+	var sum float64
+	var count int
+	for load, loadingReadings := range loading {
+		unloadingReadings, ok := unloading[load]
+		if !ok {
+			continue
+		}
+		fraction := load / peakLoad
+		sum += (average(unloadingReadings) - average(loadingReadings)) / (peakLoad * 4 * fraction * (1 - fraction))
+		count++
+	}
+	if count > 0 {
+		loadCompensation.HysteresisCoefficient = sum / float64(count)
+	}
+
+	return loadCompensation, nil
+}
+
+// average returns the average of values
+func average(values []float64) float64 {
+	if len(values) < 1 {
+		return 0
+	}
+	var sum float64
+	for i := range values {
+		sum += values[i]
+	}
+	return sum / float64(len(values))
+}
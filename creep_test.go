@@ -0,0 +1,89 @@
+package hx711
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// holdSamples returns samples of an empty scale, a hold at load, then empty again,
+// with readings off by offset plus creep settling with timeConstant
+func holdSamples(load float64, offset float64, creep float64, timeConstant time.Duration) []LoadSample {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	var samples []LoadSample
+	for i := 0; i < 10; i++ {
+		samples = append(samples, LoadSample{Time: start.Add(time.Duration(i) * time.Second)})
+	}
+	holdStart := start.Add(10 * time.Second)
+	for i := 0; i <= 100; i++ {
+		elapsed := time.Duration(i) * time.Second
+		reading := load + offset + creep*(1-math.Exp(-elapsed.Seconds()/timeConstant.Seconds()))
+		samples = append(samples, LoadSample{Time: holdStart.Add(elapsed), Reading: reading, Load: load})
+	}
+	holdEnd := holdStart.Add(100 * time.Second)
+	for i := 1; i <= 10; i++ {
+		samples = append(samples, LoadSample{Time: holdEnd.Add(time.Duration(i) * time.Second)})
+	}
+	return samples
+}
+
+func TestEstimateLoadCompensation(t *testing.T) {
+	tests := []struct {
+		name         string
+		load         float64
+		offset       float64
+		creep        float64
+		timeConstant time.Duration
+	}{
+		{name: "no offset", load: 100, offset: 0, creep: 0.5, timeConstant: 10 * time.Second},
+		{name: "positive offset", load: 100, offset: 1, creep: 0.5, timeConstant: 10 * time.Second},
+		{name: "negative offset", load: 100, offset: -2, creep: 0.5, timeConstant: 10 * time.Second},
+		{name: "negative creep", load: 200, offset: 1, creep: -0.8, timeConstant: 5 * time.Second},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			loadCompensation, err := EstimateLoadCompensation(holdSamples(test.load, test.offset, test.creep, test.timeConstant), 1)
+			if err != nil {
+				t.Fatalf("EstimateLoadCompensation error: %v", err)
+			}
+
+			wantCoefficient := test.creep / test.load
+			if math.Abs(loadCompensation.CreepCoefficient-wantCoefficient) > math.Abs(wantCoefficient)*0.01 {
+				t.Errorf("CreepCoefficient got %v want %v", loadCompensation.CreepCoefficient, wantCoefficient)
+			}
+			gotTimeConstant := loadCompensation.CreepTimeConstant.Seconds()
+			if math.Abs(gotTimeConstant-test.timeConstant.Seconds()) > test.timeConstant.Seconds()*0.05 {
+				t.Errorf("CreepTimeConstant got %v want %v", loadCompensation.CreepTimeConstant, test.timeConstant)
+			}
+			if loadCompensation.HysteresisCoefficient != 0 {
+				t.Errorf("HysteresisCoefficient got %v want 0", loadCompensation.HysteresisCoefficient)
+			}
+		})
+	}
+}
+
+func TestEstimateLoadCompensationNoHold(t *testing.T) {
+	samples := holdSamples(100, 0, 0.5, 10*time.Second)[:12]
+	_, err := EstimateLoadCompensation(samples, 1)
+	if err == nil {
+		t.Fatal("EstimateLoadCompensation expected error")
+	}
+}
+
+func TestLoadCompensationCompensate(t *testing.T) {
+	loadCompensation := &LoadCompensation{
+		CreepCoefficient:  0.005,
+		CreepTimeConstant: 10 * time.Second,
+		StepThreshold:     1,
+	}
+
+	// after a long hold the compensated reading should be back at the load
+	var compensated float64
+	for _, sample := range holdSamples(100, 0, 0.5, 10*time.Second)[:111] {
+		compensated = loadCompensation.Compensate(sample.Reading, sample.Time)
+	}
+	if math.Abs(compensated-100) > 0.01 {
+		t.Errorf("Compensate got %v want 100", compensated)
+	}
+}
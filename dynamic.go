@@ -0,0 +1,207 @@
+package hx711
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	// defaultTrimFraction is the default fraction of the on platform window dropped at each end
+	defaultTrimFraction = 0.2
+	// defaultDynamicMinSamples is the default fewest plateau samples for a weight estimate
+	defaultDynamicMinSamples = 5
+	// outlierMADs is how many scaled median absolute deviations from the median is an outlier
+	outlierMADs = 3
+)
+
+// DynamicResult is a weight estimate from a DynamicWeigher
+type DynamicResult struct {
+	Weight float64
+	// Uncertainty is the standard uncertainty of Weight, from the noise, oscillation, and drift of the plateau
+	Uncertainty float64
+	// Start is when the load came on the platform
+	Start time.Time
+	// End is when the load left the platform
+	End time.Time
+	// Samples is the number of samples used for the estimate
+	Samples int
+}
+
+// DynamicWeigher weighs loads that do not stay still, such as parcels on a moving belt or a restless animal.
+// It detects when a load is on the platform, drops the entry and exit transients,
+// rejects outliers, averages over whole oscillation cycles, and reports the weight when the load leaves.
+// Pass it readings, such as from BackgroundReadings with numReadings of 1, with Update.
+type DynamicWeigher struct {
+	// OnThreshold is the reading above which a load is on the platform
+	OnThreshold float64
+	// OffThreshold is the reading below which the load has left the platform, default is OnThreshold
+	OffThreshold float64
+	// TrimFraction is the fraction of the on platform window dropped at each end, default is 0.2
+	TrimFraction float64
+	// MinSamples is the fewest samples left after trimming for a weight estimate, default is 5
+	MinSamples int
+	// Callback if set is called with each result, from the goroutine calling Update
+	Callback func(DynamicResult)
+
+	on      bool
+	samples []Reading
+}
+
+// OnPlatform returns true if a load is on the platform
+func (dynamicWeigher *DynamicWeigher) OnPlatform() bool {
+	return dynamicWeigher.on
+}
+
+// Update adds a reading. Readings need to be passed in time order.
+// Returns the result and true when a load has left the platform.
+// Returns an error when a load left the platform without enough samples for an estimate.
+func (dynamicWeigher *DynamicWeigher) Update(reading Reading) (DynamicResult, bool, error) {
+	if !dynamicWeigher.on {
+		if reading.Value > dynamicWeigher.OnThreshold {
+			dynamicWeigher.on = true
+			dynamicWeigher.samples = append(dynamicWeigher.samples[:0], reading)
+		}
+		return DynamicResult{}, false, nil
+	}
+
+	offThreshold := dynamicWeigher.OffThreshold
+	if offThreshold == 0 {
+		offThreshold = dynamicWeigher.OnThreshold
+	}
+	if reading.Value >= offThreshold {
+		dynamicWeigher.samples = append(dynamicWeigher.samples, reading)
+		return DynamicResult{}, false, nil
+	}
+
+	dynamicWeigher.on = false
+	result, err := dynamicWeigher.estimate(reading.Time)
+	if err != nil {
+		return DynamicResult{}, false, err
+	}
+	if dynamicWeigher.Callback != nil {
+		dynamicWeigher.Callback(result)
+	}
+	return result, true, nil
+}
+
+// estimate estimates the plateau weight of the on platform samples
+func (dynamicWeigher *DynamicWeigher) estimate(end time.Time) (DynamicResult, error) {
+	trimFraction := dynamicWeigher.TrimFraction
+	if trimFraction <= 0 || trimFraction >= 0.5 {
+		trimFraction = defaultTrimFraction
+	}
+	minSamples := dynamicWeigher.MinSamples
+	if minSamples < 2 {
+		minSamples = defaultDynamicMinSamples
+	}
+
+	result := DynamicResult{Start: dynamicWeigher.samples[0].Time, End: end}
+
+	// drop entry and exit transients
+	trim := int(float64(len(dynamicWeigher.samples)) * trimFraction)
+	plateau := dynamicWeigher.samples[trim : len(dynamicWeigher.samples)-trim]
+	if len(plateau) < minSamples {
+		return result, fmt.Errorf("only %v samples on platform after trimming", len(plateau))
+	}
+
+	// reject outliers using the median absolute deviation
+	values := make([]float64, len(plateau))
+	for i := range plateau {
+		values[i] = plateau[i].Value
+	}
+	median := medianFloat64(values)
+	deviations := make([]float64, len(values))
+	for i := range values {
+		deviations[i] = math.Abs(values[i] - median)
+	}
+	limit := outlierLimit(deviations)
+	kept := make([]Reading, 0, len(plateau))
+	for i := range plateau {
+		if limit == 0 || math.Abs(plateau[i].Value-median) <= limit {
+			kept = append(kept, plateau[i])
+		}
+	}
+	if len(kept) < minSamples {
+		return result, fmt.Errorf("only %v samples on platform after rejecting outliers", len(kept))
+	}
+
+	// average over whole oscillation cycles, from the first to the last upward crossing of the mean
+	mean := averageReadings(kept)
+	first := -1
+	last := -1
+	cycles := -1
+	for i := 1; i < len(kept); i++ {
+		if kept[i-1].Value < mean && kept[i].Value >= mean {
+			if first < 0 {
+				first = i
+			}
+			last = i
+			cycles++
+		}
+	}
+	if cycles > 0 && last-first >= minSamples {
+		kept = kept[first:last]
+		mean = averageReadings(kept)
+	}
+
+	// noise, where oscillating samples are not independent so use about two per cycle
+	var sumSq float64
+	for i := range kept {
+		sumSq += (kept[i].Value - mean) * (kept[i].Value - mean)
+	}
+	independent := len(kept)
+	if cycles > 0 && 2*cycles < independent {
+		independent = 2 * cycles
+	}
+	standardError := math.Sqrt(sumSq/float64(len(kept)-1)) / math.Sqrt(float64(independent))
+
+	// drift over the plateau, such as a load still settling, adds to the uncertainty
+	times := make([]float64, len(kept))
+	values = values[:len(kept)]
+	for i := range kept {
+		times[i] = kept[i].Time.Sub(kept[0].Time).Seconds()
+		values[i] = kept[i].Value
+	}
+	var drift float64
+	_, slope, err := linearFit(times, values)
+	if err == nil {
+		drift = slope * times[len(times)-1] / 2
+	}
+
+	result.Weight = mean
+	result.Uncertainty = math.Sqrt(standardError*standardError + drift*drift)
+	result.Samples = len(kept)
+	return result, nil
+}
+
+// outlierLimit returns the deviation beyond which a value is an outlier,
+// outlierMADs times the median of the absolute deviations scaled to a standard deviation
+func outlierLimit(deviations []float64) float64 {
+	// 1.4826 scales the median absolute deviation to a standard deviation for normal noise
+	return outlierMADs * 1.4826 * medianFloat64(deviations)
+}
+
+// medianFloat64 returns the median of values without changing values
+func medianFloat64(values []float64) float64 {
+	if len(values) < 1 {
+		return 0
+	}
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+	if len(sorted)%2 == 0 {
+		return (sorted[len(sorted)/2-1] + sorted[len(sorted)/2]) / 2
+	}
+	return sorted[len(sorted)/2]
+}
+
+// averageReadings returns the average value of readings
+func averageReadings(readings []Reading) float64 {
+	var sum float64
+	for i := range readings {
+		sum += readings[i].Value
+	}
+	return sum / float64(len(readings))
+}
@@ -0,0 +1,230 @@
+package hx711
+
+import (
+	"fmt"
+	"time"
+)
+
+// Output is an on and off output, such as a GPIO pin switching a valve
+type Output interface {
+	Set(on bool) error
+}
+
+// OutputFunc is a func that can be used as an Output
+type OutputFunc func(on bool) error
+
+// Set calls the func
+func (outputFunc OutputFunc) Set(on bool) error {
+	return outputFunc(on)
+}
+
+// FillStage is the stage of a fill
+type FillStage int
+
+const (
+	// FillIdle is before Start or after the fill is done
+	FillIdle FillStage = iota
+	// FillCoarse is feeding with both the Coarse and Fine outputs on
+	FillCoarse
+	// FillFine is feeding with only the Fine output on
+	FillFine
+	// FillSettling is waiting for the weight to settle after cut off
+	FillSettling
+)
+
+// FillResult is the result of a fill
+type FillResult struct {
+	Target float64
+	// Final is the settled weight after cut off
+	Final float64
+	// Error is Final minus Target
+	Error float64
+	// InFlight is the in-flight amount used to cut off this fill
+	InFlight float64
+	Start    time.Time
+	End      time.Time
+	// TimedOut is true when the fill was stopped because it took longer than Timeout
+	TimedOut bool
+}
+
+// FillController fills to a target weight by switching coarse and fine feed outputs.
+// Feed is cut off early by InFlight, the material still falling after cut off,
+// and InFlight is learned from the overshoot of each fill.
+// Readings should be net, with the container tared.
+// Call Start, then pass it readings, such as from BackgroundReadings, with Update.
+type FillController struct {
+	Target float64
+	// FineThreshold is how far below Target to switch from coarse to fine feed
+	FineThreshold float64
+	// Coarse is optional, the coarse feed output, on along with Fine during the coarse stage
+	Coarse Output
+	// Fine is the fine feed output
+	Fine Output
+	// InFlight is the amount still falling after cut off, learned after each fill
+	InFlight float64
+	// LearnRate is the fraction, 0 to 1, of each fill's error added to InFlight. 0 does not learn.
+	LearnRate float64
+	// Stabilizer decides when the weight has settled after cut off
+	Stabilizer Stabilizer
+	// Timeout is the longest a fill can take, 0 for no timeout
+	Timeout time.Duration
+	// Callback if set is called with each fill result, from the goroutine calling Update
+	Callback func(FillResult)
+
+	stage    FillStage
+	start    time.Time
+	inFlight float64
+}
+
+// String returns the name of the fill stage
+func (fillStage FillStage) String() string {
+	switch fillStage {
+	case FillIdle:
+		return "idle"
+	case FillCoarse:
+		return "coarse"
+	case FillFine:
+		return "fine"
+	case FillSettling:
+		return "settling"
+	}
+	return "unknown"
+}
+
+// Stage returns the current stage of the fill
+func (fillController *FillController) Stage() FillStage {
+	return fillController.stage
+}
+
+// Start starts a fill at startTime, turning on the feed outputs
+func (fillController *FillController) Start(startTime time.Time) error {
+	if fillController.Fine == nil {
+		return fmt.Errorf("Fine output is nil")
+	}
+	if fillController.stage != FillIdle {
+		return fmt.Errorf("fill already started")
+	}
+
+	fillController.start = startTime
+	fillController.inFlight = fillController.InFlight
+	fillController.Stabilizer.Reset()
+
+	if fillController.Coarse != nil && fillController.FineThreshold > 0 {
+		fillController.stage = FillCoarse
+		err := fillController.Coarse.Set(true)
+		if err != nil {
+			fillController.Stop()
+			return fmt.Errorf("Coarse Set error: %v", err)
+		}
+	} else {
+		fillController.stage = FillFine
+	}
+
+	err := fillController.Fine.Set(true)
+	if err != nil {
+		fillController.Stop()
+		return fmt.Errorf("Fine Set error: %v", err)
+	}
+
+	return nil
+}
+
+// Stop aborts the fill and turns off the feed outputs
+func (fillController *FillController) Stop() error {
+	fillController.stage = FillIdle
+	return fillController.feedOff()
+}
+
+// feedOff turns off the feed outputs
+func (fillController *FillController) feedOff() error {
+	var err error
+	if fillController.Coarse != nil {
+		err = fillController.Coarse.Set(false)
+		if err != nil {
+			err = fmt.Errorf("Coarse Set error: %v", err)
+		}
+	}
+	if fillController.Fine != nil {
+		fineErr := fillController.Fine.Set(false)
+		if fineErr != nil {
+			err = fmt.Errorf("Fine Set error: %v", fineErr)
+		}
+	}
+	return err
+}
+
+// Update adds a reading. Readings need to be passed in time order.
+// Returns the result and true when the fill is done.
+func (fillController *FillController) Update(reading Reading) (FillResult, bool, error) {
+	if fillController.stage == FillIdle {
+		return FillResult{}, false, nil
+	}
+
+	if fillController.Timeout > 0 && reading.Time.Sub(fillController.start) > fillController.Timeout {
+		err := fillController.Stop()
+		result := fillController.result(reading.Value, reading.Time)
+		result.TimedOut = true
+		if fillController.Callback != nil {
+			fillController.Callback(result)
+		}
+		return result, true, err
+	}
+
+	switch fillController.stage {
+	case FillCoarse:
+		if reading.Value < fillController.Target-fillController.FineThreshold {
+			return FillResult{}, false, nil
+		}
+		fillController.stage = FillFine
+		err := fillController.Coarse.Set(false)
+		if err != nil {
+			fillController.Stop()
+			return FillResult{}, false, fmt.Errorf("Coarse Set error: %v", err)
+		}
+		fallthrough
+
+	case FillFine:
+		if reading.Value < fillController.Target-fillController.inFlight {
+			return FillResult{}, false, nil
+		}
+		fillController.stage = FillSettling
+		err := fillController.feedOff()
+		if err != nil {
+			fillController.stage = FillIdle
+			return FillResult{}, false, err
+		}
+		return FillResult{}, false, nil
+
+	case FillSettling:
+		final, stable := fillController.Stabilizer.Update(reading)
+		if !stable {
+			return FillResult{}, false, nil
+		}
+		fillController.stage = FillIdle
+		result := fillController.result(final, reading.Time)
+
+		fillController.InFlight += fillController.LearnRate * result.Error
+		if fillController.InFlight < 0 {
+			fillController.InFlight = 0
+		}
+
+		if fillController.Callback != nil {
+			fillController.Callback(result)
+		}
+		return result, true, nil
+	}
+
+	return FillResult{}, false, nil
+}
+
+// result returns the fill result for final weight at endTime
+func (fillController *FillController) result(final float64, endTime time.Time) FillResult {
+	return FillResult{
+		Target:   fillController.Target,
+		Final:    final,
+		Error:    final - fillController.Target,
+		InFlight: fillController.inFlight,
+		Start:    fillController.start,
+		End:      endTime,
+	}
+}
@@ -0,0 +1,224 @@
+package hx711
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+)
+
+// fillProcess simulates a feeder filling a container on a scale
+type fillProcess struct {
+	coarseRate float64
+	fineRate   float64
+	inFlight   float64
+
+	coarseOn bool
+	fineOn   bool
+	weight   float64
+	falling  float64
+	switches []string
+}
+
+// outputs returns the coarse and fine outputs of the process
+func (process *fillProcess) outputs() (Output, Output) {
+	coarse := OutputFunc(func(on bool) error {
+		if on != process.coarseOn {
+			process.switches = append(process.switches, fmt.Sprintf("coarse %v at %v", on, process.weight))
+		}
+		process.coarseOn = on
+		return nil
+	})
+	fine := OutputFunc(func(on bool) error {
+		if on != process.fineOn {
+			process.switches = append(process.switches, fmt.Sprintf("fine %v at %v", on, process.weight))
+			if !on {
+				process.falling += process.inFlight
+			}
+		}
+		process.fineOn = on
+		return nil
+	})
+	return coarse, fine
+}
+
+// step advances the process one reading
+func (process *fillProcess) step() float64 {
+	if process.coarseOn {
+		process.weight += process.coarseRate
+	}
+	if process.fineOn {
+		process.weight += process.fineRate
+	}
+	process.weight += process.falling
+	process.falling = 0
+	return process.weight
+}
+
+// runFill runs a fill of fillController on process and returns the result
+func runFill(t *testing.T, fillController *FillController, process *fillProcess) FillResult {
+	stream := &readingStream{interval: 100 * time.Millisecond}
+	err := fillController.Start(stream.next())
+	if err != nil {
+		t.Fatalf("Start error: %v", err)
+	}
+	for i := 0; i < 1000; i++ {
+		stream.add(process.step())
+		result, done, err := fillController.Update(stream.readings[len(stream.readings)-1])
+		if err != nil {
+			t.Fatalf("Update error: %v", err)
+		}
+		if done {
+			return result
+		}
+	}
+	t.Fatal("fill not done")
+	return FillResult{}
+}
+
+func TestFillController(t *testing.T) {
+	tests := []struct {
+		name          string
+		coarse        bool
+		inFlight      float64
+		learnRate     float64
+		trueInFlight  float64
+		wantSwitches  []string
+		wantFinal     float64
+		wantInFlight  float64
+		wantNextFinal float64
+	}{
+		{
+			name:          "fine only no in-flight",
+			trueInFlight:  0,
+			wantSwitches:  []string{"fine true at 0", "fine false at 100"},
+			wantFinal:     100,
+			wantNextFinal: 100,
+		},
+		{
+			name:          "coarse and fine",
+			coarse:        true,
+			trueInFlight:  0,
+			wantSwitches:  []string{"coarse true at 0", "fine true at 0", "coarse false at 88", "fine false at 100"},
+			wantFinal:     100,
+			wantNextFinal: 100,
+		},
+		{
+			name:          "overshoot without learning",
+			coarse:        true,
+			trueInFlight:  3,
+			wantSwitches:  []string{"coarse true at 0", "fine true at 0", "coarse false at 88", "fine false at 100"},
+			wantFinal:     103,
+			wantNextFinal: 103,
+		},
+		{
+			name:          "learns in-flight",
+			coarse:        true,
+			learnRate:     1,
+			trueInFlight:  3,
+			wantSwitches:  []string{"coarse true at 0", "fine true at 0", "coarse false at 88", "fine false at 100"},
+			wantFinal:     103,
+			wantInFlight:  3,
+			wantNextFinal: 100,
+		},
+		{
+			name:          "preset in-flight",
+			coarse:        true,
+			inFlight:      3,
+			trueInFlight:  3,
+			wantSwitches:  []string{"coarse true at 0", "fine true at 0", "coarse false at 88", "fine false at 97"},
+			wantFinal:     100,
+			wantInFlight:  3,
+			wantNextFinal: 100,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			process := &fillProcess{coarseRate: 10, fineRate: 1, inFlight: test.trueInFlight}
+			coarse, fine := process.outputs()
+			fillController := &FillController{
+				Target:        100,
+				FineThreshold: 20,
+				Fine:          fine,
+				InFlight:      test.inFlight,
+				LearnRate:     test.learnRate,
+				Stabilizer:    Stabilizer{Tolerance: 0.1, Duration: 300 * time.Millisecond},
+			}
+			if test.coarse {
+				fillController.Coarse = coarse
+			}
+
+			result := runFill(t, fillController, process)
+
+			if fmt.Sprint(process.switches) != fmt.Sprint(test.wantSwitches) {
+				t.Errorf("switches got %v want %v", process.switches, test.wantSwitches)
+			}
+			if math.Abs(result.Final-test.wantFinal) > 1e-9 || math.Abs(result.Error-(test.wantFinal-100)) > 1e-9 {
+				t.Errorf("result got %+v want Final %v", result, test.wantFinal)
+			}
+			if result.TimedOut {
+				t.Error("TimedOut is true")
+			}
+			if fillController.Stage() != FillIdle {
+				t.Errorf("Stage got %v want %v", fillController.Stage(), FillIdle)
+			}
+			if math.Abs(fillController.InFlight-test.wantInFlight) > 1e-9 {
+				t.Errorf("InFlight got %v want %v", fillController.InFlight, test.wantInFlight)
+			}
+
+			// fill again into an empty container
+			process.weight = 0
+			process.switches = nil
+			result = runFill(t, fillController, process)
+			if math.Abs(result.Final-test.wantNextFinal) > 1e-9 {
+				t.Errorf("next Final got %v want %v", result.Final, test.wantNextFinal)
+			}
+		})
+	}
+}
+
+func TestFillControllerTimeout(t *testing.T) {
+	// a blocked feeder that adds nothing
+	process := &fillProcess{}
+	coarse, fine := process.outputs()
+	fillController := &FillController{
+		Target:        100,
+		FineThreshold: 20,
+		Coarse:        coarse,
+		Fine:          fine,
+		Timeout:       time.Second,
+	}
+
+	result := runFill(t, fillController, process)
+	if !result.TimedOut {
+		t.Error("TimedOut is false")
+	}
+	if process.coarseOn || process.fineOn {
+		t.Error("outputs still on after timeout")
+	}
+	if fillController.Stage() != FillIdle {
+		t.Errorf("Stage got %v want %v", fillController.Stage(), FillIdle)
+	}
+}
+
+func TestFillControllerOutputError(t *testing.T) {
+	var fineOn bool
+	fillController := &FillController{
+		Target:        100,
+		FineThreshold: 20,
+		Coarse:        OutputFunc(func(on bool) error { return fmt.Errorf("relay fault") }),
+		Fine:          OutputFunc(func(on bool) error { fineOn = on; return nil }),
+	}
+
+	err := fillController.Start(testStart)
+	if err == nil {
+		t.Fatal("Start expected error")
+	}
+	if fineOn {
+		t.Error("fine output on after Start error")
+	}
+	if fillController.Stage() != FillIdle {
+		t.Errorf("Stage got %v want %v", fillController.Stage(), FillIdle)
+	}
+}
@@ -0,0 +1,109 @@
+package hx711
+
+import (
+	"fmt"
+	"math"
+)
+
+// theilSenPoints is the most points used for the pairs of a theilSenFit
+const theilSenPoints = 100
+
+// linearFit does a least squares fit of y = intercept + slope * x
+func linearFit(xs []float64, ys []float64) (float64, float64, error) {
+	if len(xs) != len(ys) {
+		return 0, 0, fmt.Errorf("xs and ys are not the same length")
+	}
+	if len(xs) < 2 {
+		return 0, 0, fmt.Errorf("need at least 2 points")
+	}
+
+	var meanX float64
+	var meanY float64
+	for i := range xs {
+		meanX += xs[i]
+		meanY += ys[i]
+	}
+	meanX /= float64(len(xs))
+	meanY /= float64(len(ys))
+
+	var sumXX float64
+	var sumXY float64
+	for i := range xs {
+		sumXX += (xs[i] - meanX) * (xs[i] - meanX)
+		sumXY += (xs[i] - meanX) * (ys[i] - meanY)
+	}
+	if sumXX == 0 {
+		return 0, 0, fmt.Errorf("all x values are the same")
+	}
+
+	slope := sumXY / sumXX
+	return meanY - slope*meanX, slope, nil
+}
+
+// robustLinearFit fits a line that is not thrown off by outliers.
+// It starts with a theilSenFit, drops points more than outlierLimit from that line,
+// then does a linearFit of the rest.
+func robustLinearFit(xs []float64, ys []float64) (float64, float64, error) {
+	intercept, slope, err := theilSenFit(xs, ys)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	residuals := make([]float64, len(xs))
+	for i := range xs {
+		residuals[i] = math.Abs(ys[i] - intercept - slope*xs[i])
+	}
+	limit := outlierLimit(residuals)
+
+	keptXs := make([]float64, 0, len(xs))
+	keptYs := make([]float64, 0, len(ys))
+	for i := range xs {
+		if residuals[i] <= limit {
+			keptXs = append(keptXs, xs[i])
+			keptYs = append(keptYs, ys[i])
+		}
+	}
+
+	keptIntercept, keptSlope, err := linearFit(keptXs, keptYs)
+	if err != nil {
+		// too few points left to fit, such as all but one at the same x
+		return intercept, slope, nil
+	}
+	return keptIntercept, keptSlope, nil
+}
+
+// theilSenFit fits a line with the median of the slopes between each pair of points
+// and the median of the intercepts, which is not thrown off by up to about a quarter of the points being outliers.
+// Long series are thinned to theilSenPoints evenly spaced points to limit the number of pairs.
+func theilSenFit(xs []float64, ys []float64) (float64, float64, error) {
+	if len(xs) != len(ys) {
+		return 0, 0, fmt.Errorf("xs and ys are not the same length")
+	}
+	if len(xs) < 2 {
+		return 0, 0, fmt.Errorf("need at least 2 points")
+	}
+
+	step := 1
+	if len(xs) > theilSenPoints {
+		step = (len(xs) + theilSenPoints - 1) / theilSenPoints
+	}
+
+	var slopes []float64
+	for i := 0; i < len(xs); i += step {
+		for j := i + step; j < len(xs); j += step {
+			if xs[j] != xs[i] {
+				slopes = append(slopes, (ys[j]-ys[i])/(xs[j]-xs[i]))
+			}
+		}
+	}
+	if len(slopes) < 1 {
+		return 0, 0, fmt.Errorf("all x values are the same")
+	}
+	slope := medianFloat64(slopes)
+
+	intercepts := make([]float64, len(xs))
+	for i := range xs {
+		intercepts[i] = ys[i] - slope*xs[i]
+	}
+	return medianFloat64(intercepts), slope, nil
+}
@@ -0,0 +1,179 @@
+package hx711
+
+import (
+	"fmt"
+)
+
+// Gain is the gain of the chip amplifier
+type Gain int
+
+const (
+	// Gain128 is gain of 128, channel A
+	Gain128 Gain = 128
+	// Gain64 is gain of 64, channel A
+	Gain64 Gain = 64
+	// Gain32 is gain of 32, channel B
+	Gain32 Gain = 32
+)
+
+// Channel is the chip input channel
+type Channel int
+
+const (
+	// ChannelA is input channel A, gain of 128 or 64
+	ChannelA Channel = iota
+	// ChannelB is input channel B, gain of 32
+	ChannelB
+)
+
+// Calibration is the adjust values for readings taken at Gain
+type Calibration struct {
+	Gain        Gain
+	AdjustZero  int
+	AdjustScale float64
+}
+
+// ErrNoCalibration is returned when calibrations have been set but none are for the gain of the reading
+var ErrNoCalibration = fmt.Errorf("no calibration for gain")
+
+// String returns the name of the channel
+func (channel Channel) String() string {
+	switch channel {
+	case ChannelA:
+		return "A"
+	case ChannelB:
+		return "B"
+	}
+	return "unknown"
+}
+
+// channelGainEndPulses returns the number of end pulses that select channel and gain
+func channelGainEndPulses(channel Channel, gain Gain) (int, error) {
+	switch {
+	case channel == ChannelA && gain == Gain128:
+		return 1, nil
+	case channel == ChannelA && gain == Gain64:
+		return 3, nil
+	case channel == ChannelB && gain == Gain32:
+		return 2, nil
+	}
+	return 0, fmt.Errorf("channel %v with gain of %v is not supported", channel, int(gain))
+}
+
+// endPulsesChannelGain returns the channel and gain selected by the number of end pulses.
+// After power up the chip is at channel A with gain of 128.
+func endPulsesChannelGain(endPulses int) (Channel, Gain) {
+	switch endPulses {
+	case 2:
+		return ChannelB, Gain32
+	case 3:
+		return ChannelA, Gain64
+	}
+	return ChannelA, Gain128
+}
+
+// SetGain can be set to gain of 128, 64, or 32.
+// Gain of 128 or 64 is input channel A, gain of 32 is input channel B.
+// Default gain is 128.
+// Returns an error and keeps the current gain if gain is not supported.
+// The chip changes gain after the next reading, readings are thrown away until the chip has settled at the new gain.
+func (hx711 *Hx711) SetGain(gain int) error {
+	channel := ChannelA
+	if gain == int(Gain32) {
+		channel = ChannelB
+	}
+	return hx711.SetChannelGain(channel, Gain(gain))
+}
+
+// SetChannelGain sets the input channel and gain.
+// Channel A can be gain of 128 or 64, channel B can only be gain of 32.
+// Returns an error and keeps the current channel and gain if not supported.
+// The chip changes gain after the next reading, readings are thrown away until the chip has settled at the new gain.
+func (hx711 *Hx711) SetChannelGain(channel Channel, gain Gain) error {
+	endPulses, err := channelGainEndPulses(channel, gain)
+	if err != nil {
+		return err
+	}
+	hx711.numEndPulses = endPulses
+	return nil
+}
+
+// RequestedGain returns the channel and gain set with SetGain or SetChannelGain
+func (hx711 *Hx711) RequestedGain() (Channel, Gain) {
+	return endPulsesChannelGain(hx711.numEndPulses)
+}
+
+// ActiveGain returns the channel and gain the chip is currently converting at.
+// This can be different than RequestedGain until the next reading.
+func (hx711 *Hx711) ActiveGain() (Channel, Gain) {
+	return endPulsesChannelGain(hx711.activeEndPulses)
+}
+
+// SetCalibration sets the adjust values for readings taken at calibration.Gain.
+// Once any calibration is set, readings are adjusted with the calibration for their gain,
+// instead of AdjustZero and AdjustScale, and readings at a gain without a calibration return ErrNoCalibration.
+func (hx711 *Hx711) SetCalibration(calibration Calibration) error {
+	if calibration.Gain != Gain128 && calibration.Gain != Gain64 && calibration.Gain != Gain32 {
+		return fmt.Errorf("gain of %v is not supported", int(calibration.Gain))
+	}
+	if calibration.AdjustScale == 0 {
+		return fmt.Errorf("AdjustScale is 0")
+	}
+	if hx711.calibrations == nil {
+		hx711.calibrations = make(map[Gain]Calibration)
+	}
+	hx711.calibrations[calibration.Gain] = calibration
+	return nil
+}
+
+// CalibrationFor returns the calibration set for gain
+func (hx711 *Hx711) CalibrationFor(gain Gain) (Calibration, bool) {
+	calibration, ok := hx711.calibrations[gain]
+	return calibration, ok
+}
+
+// adjustCalibration returns AdjustZero and AdjustScale as a Calibration at AdjustGain
+func (hx711 *Hx711) adjustCalibration() Calibration {
+	gain := hx711.AdjustGain
+	if gain == 0 {
+		gain = Gain128
+	}
+	return Calibration{Gain: gain, AdjustZero: hx711.AdjustZero, AdjustScale: hx711.AdjustScale}
+}
+
+// adjustCalibrationB returns AdjustZeroB and AdjustScaleB as a Calibration at gain of 32
+func (hx711 *Hx711) adjustCalibrationB() Calibration {
+	return Calibration{Gain: Gain32, AdjustZero: hx711.AdjustZeroB, AdjustScale: hx711.AdjustScaleB}
+}
+
+// adjustValues returns the adjust values for gain from the calibrations set with SetCalibration.
+// If no calibrations have been set, returns the values of adjust, which are usually from adjustCalibration,
+// or ErrNoCalibration if adjust is for a different gain.
+// If TemperatureCompensation is set for gain, the adjust values are corrected for temperature.
+func (hx711 *Hx711) adjustValues(gain Gain, adjust Calibration) (float64, float64, error) {
+	if len(hx711.calibrations) > 0 {
+		calibration, ok := hx711.calibrations[gain]
+		if !ok {
+			return 0, 0, ErrNoCalibration
+		}
+		adjust = calibration
+	} else if adjust.Gain != gain {
+		return 0, 0, ErrNoCalibration
+	}
+
+	if hx711.TemperatureCompensation != nil && hx711.TemperatureCompensation.appliesTo(gain) {
+		return hx711.TemperatureCompensation.compensate(float64(adjust.AdjustZero), adjust.AdjustScale)
+	}
+
+	return float64(adjust.AdjustZero), adjust.AdjustScale, nil
+}
+
+// adjustData adjusts raw data taken at gain with the calibration for gain,
+// or adjust if no calibrations have been set
+func (hx711 *Hx711) adjustData(data int, gain Gain, adjust Calibration) (float64, error) {
+	zero, scale, err := hx711.adjustValues(gain, adjust)
+	if err != nil {
+		return 0, err
+	}
+	return (float64(data) - zero) / scale, nil
+}
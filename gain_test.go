@@ -0,0 +1,143 @@
+package hx711
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAdjustValues(t *testing.T) {
+	temperatureCompensation := &TemperatureCompensation{
+		Source:               TemperatureFunc(func() (float64, error) { return 30, nil }),
+		ReferenceTemperature: 20,
+		ZeroCoefficient:      10,
+		Gain:                 Gain64,
+	}
+
+	tests := []struct {
+		name         string
+		adjustGain   Gain
+		calibrations []Calibration
+		compensation *TemperatureCompensation
+		gain         Gain
+		channelB     bool
+		wantZero     float64
+		wantScale    float64
+		wantErr      error
+	}{
+		{name: "default gain", gain: Gain128, wantZero: 1000, wantScale: 10},
+		{name: "default gain at 64", gain: Gain64, wantErr: ErrNoCalibration},
+		{name: "AdjustGain of 64", adjustGain: Gain64, gain: Gain64, wantZero: 1000, wantScale: 10},
+		{name: "AdjustGain of 64 at 128", adjustGain: Gain64, gain: Gain128, wantErr: ErrNoCalibration},
+		{name: "channel B", gain: Gain32, channelB: true, wantZero: 200, wantScale: 2},
+		{
+			name:         "calibration for gain",
+			calibrations: []Calibration{{Gain: Gain128, AdjustZero: 3000, AdjustScale: 30}, {Gain: Gain64, AdjustZero: 1500, AdjustScale: 15}},
+			gain:         Gain64,
+			wantZero:     1500,
+			wantScale:    15,
+		},
+		{
+			name:         "calibrations replace AdjustZero and AdjustScale",
+			calibrations: []Calibration{{Gain: Gain64, AdjustZero: 1500, AdjustScale: 15}},
+			gain:         Gain128,
+			wantErr:      ErrNoCalibration,
+		},
+		{
+			name:         "calibrations replace AdjustZeroB and AdjustScaleB",
+			calibrations: []Calibration{{Gain: Gain128, AdjustZero: 3000, AdjustScale: 30}},
+			gain:         Gain32,
+			channelB:     true,
+			wantErr:      ErrNoCalibration,
+		},
+		{
+			name:         "temperature compensation at its gain",
+			adjustGain:   Gain64,
+			compensation: temperatureCompensation,
+			gain:         Gain64,
+			wantZero:     1100,
+			wantScale:    10,
+		},
+		{
+			name:         "temperature compensation at other gain",
+			calibrations: []Calibration{{Gain: Gain128, AdjustZero: 3000, AdjustScale: 30}},
+			compensation: temperatureCompensation,
+			gain:         Gain128,
+			wantZero:     3000,
+			wantScale:    30,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			hx711 := &Hx711{
+				AdjustZero:              1000,
+				AdjustScale:             10,
+				AdjustGain:              test.adjustGain,
+				AdjustZeroB:             200,
+				AdjustScaleB:            2,
+				TemperatureCompensation: test.compensation,
+			}
+			for _, calibration := range test.calibrations {
+				err := hx711.SetCalibration(calibration)
+				if err != nil {
+					t.Fatalf("SetCalibration error: %v", err)
+				}
+			}
+
+			adjust := hx711.adjustCalibration()
+			if test.channelB {
+				adjust = hx711.adjustCalibrationB()
+			}
+			zero, scale, err := hx711.adjustValues(test.gain, adjust)
+			if err != test.wantErr {
+				t.Fatalf("adjustValues error got %v want %v", err, test.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if math.Abs(zero-test.wantZero) > 1e-9 || math.Abs(scale-test.wantScale) > 1e-9 {
+				t.Errorf("adjustValues got %v %v want %v %v", zero, scale, test.wantZero, test.wantScale)
+			}
+		})
+	}
+}
+
+func TestSetGain(t *testing.T) {
+	tests := []struct {
+		gain        int
+		wantChannel Channel
+		wantGain    Gain
+		wantErr     bool
+	}{
+		{gain: 128, wantChannel: ChannelA, wantGain: Gain128},
+		{gain: 64, wantChannel: ChannelA, wantGain: Gain64},
+		{gain: 32, wantChannel: ChannelB, wantGain: Gain32},
+		{gain: 100, wantChannel: ChannelA, wantGain: Gain64, wantErr: true},
+	}
+
+	hx711 := &Hx711{numEndPulses: 1}
+	for _, test := range tests {
+		// an unsupported gain keeps the current gain of 64
+		if test.wantErr {
+			hx711.SetGain(64)
+		}
+		err := hx711.SetGain(test.gain)
+		if (err != nil) != test.wantErr {
+			t.Errorf("SetGain %v error got %v want error %v", test.gain, err, test.wantErr)
+		}
+		channel, gain := hx711.RequestedGain()
+		if channel != test.wantChannel || gain != test.wantGain {
+			t.Errorf("SetGain %v RequestedGain got %v %v want %v %v", test.gain, channel, gain, test.wantChannel, test.wantGain)
+		}
+	}
+
+	if hx711.SetChannelGain(ChannelB, Gain128) == nil {
+		t.Error("SetChannelGain channel B gain of 128 expected error")
+	}
+	if hx711.SetCalibration(Calibration{Gain: 100, AdjustScale: 1}) == nil {
+		t.Error("SetCalibration gain of 100 expected error")
+	}
+	if hx711.SetCalibration(Calibration{Gain: Gain128}) == nil {
+		t.Error("SetCalibration AdjustScale of 0 expected error")
+	}
+}
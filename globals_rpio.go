@@ -3,6 +3,9 @@
 package hx711
 
 import (
+	"sync"
+	"time"
+
 	"github.com/stianeikeland/go-rpio/v4"
 )
 
@@ -11,9 +14,77 @@ import (
 type Hx711 struct {
 	clockPin     rpio.Pin
 	dataPin      rpio.Pin
+	ratePin      rpio.Pin
+	hasRatePin   bool
 	numEndPulses int
 	// AdjustZero should be set to an int that will zero out a raw reading
 	AdjustZero int
 	// AdjustScale should be set to a float64 that will give output units wanted
 	AdjustScale float64
+	// AdjustGain is the gain AdjustZero and AdjustScale were taken at, default of 0 is gain of 128.
+	// Readings at any other channel A gain return ErrNoCalibration unless a calibration is set for it.
+	AdjustGain Gain
+	// Unit is the unit AdjustScale gives readings in, used by ReadWeight and ToWeight
+	Unit Unit
+	// DisplayUnit is the unit ReadWeight and ToWeight return, default is Unit
+	DisplayUnit Unit
+	// Division is the display resolution, in DisplayUnit, that ReadWeight and ToWeight round to.
+	// Must be 1, 2, or 5 times a power of 10, like 0.01, 0.2, or 5. Default of 0 does not round.
+	Division float64
+	// Capacity is the maximum load of the scale in DisplayUnit, 0 for no limit
+	Capacity float64
+	// Tank if set converts weight to liquid volume and fill level for ReadTankLevel
+	Tank *Tank
+	// Tare is the container weight in DisplayUnit subtracted for the net weight, set by SetTare
+	Tare float64
+	// CalibrationID identifies the calibration in Weighing records, such as a certificate number
+	CalibrationID string
+	// AdjustZeroB is AdjustZero for channel B when using interleaved readings
+	AdjustZeroB int
+	// AdjustScaleB is AdjustScale for channel B when using interleaved readings
+	AdjustScaleB float64
+	// TemperatureCompensation if set adjusts readings for temperature
+	TemperatureCompensation *TemperatureCompensation
+	// LoadCompensation if set corrects channel A readings for load cell creep and hysteresis
+	LoadCompensation *LoadCompensation
+	// ReadRetries is the number of times ReadDataRaw will retry a reading that was corrupted
+	// because the clock pin was held high for too long, default is 3
+	ReadRetries int
+	// MaxClockHigh is the longest the clock pin can be measured high before a reading is thrown away as corrupt,
+	// default is 60 microseconds. The measurement includes the time to set the pin, so slow pins can go over
+	// on readings that are fine. 0 turns off the check.
+	MaxClockHigh time.Duration
+	// LockOSThread locks the goroutine to its OS thread while ReadDataRaw is reading
+	LockOSThread bool
+	// RealTimePriority if above 0 sets the thread to real-time (SCHED_FIFO) priority while ReadDataRaw is reading.
+	// The thread is locked to the goroutine while reading. Needs root or CAP_SYS_NICE, Linux only
+	RealTimePriority int
+	// SamplesPerSecond is the output data rate the chip is set to by the RATE pin, 10 or 80, default is 10
+	SamplesPerSecond int
+	// ReadyTimeout is how long to wait for the chip to be ready for a reading.
+	// Default is based on SamplesPerSecond
+	ReadyTimeout time.Duration
+	// ReadyWait is how to wait for the chip to be ready for a reading
+	ReadyWait WaitStrategy
+	// ReadyPollInterval is the time between data pin checks when polling, default is 250 microseconds
+	ReadyPollInterval time.Duration
+	// IdleTimeout is how long the chip stays powered up after the last Release before it is shutdown.
+	// Default of 0 shuts down the chip right away.
+	IdleTimeout time.Duration
+
+	timingViolations int
+	poweredUp        bool
+	activeEndPulses  int
+	settleUntil      time.Time
+	gainSettleUntil  time.Time
+	sessionMutex     sync.Mutex
+	sessions         int
+	idleTimer        *time.Timer
+	idleGeneration   uint64
+	calibrations     map[Gain]Calibration
+	watchMutex       sync.Mutex
+	watchers         []*Watcher
+	product          *Product
+	checkweighers    []*Checkweigher
+	pieceCounters    []*PieceCounter
 }
@@ -5,27 +5,348 @@ package hx711
 import (
 	"fmt"
 	"log"
+	"runtime"
 	"sort"
 	"time"
 )
 
-var ErrTimeout = fmt.Errorf("timeout")
+// defaultMaxClockHigh is the longest the clock pin can be high before the chip powers down.
+// The datasheet has the chip going into power down mode after 60 microseconds.
+const defaultMaxClockHigh = 60 * time.Microsecond
+
+var (
+	// ErrTimeout is returned when the chip does not become ready in time
+	ErrTimeout = fmt.Errorf("timeout")
+	// ErrTimingViolation is returned when the clock pin was held high for too long during a reading,
+	// usually because the goroutine was descheduled, and all retries also had timing violations
+	ErrTimingViolation = fmt.Errorf("clock timing violation")
+	// ErrNoRatePin is returned when changing the rate without a RATE pin set in the Config
+	ErrNoRatePin = fmt.Errorf("no rate pin")
+)
+
+// Reset starts up or resets the chip.
+// The chip needs to be reset if it is not used for just about any amount of time.
+// Readings are thrown away until the chip has settled after the reset.
+// Stops a pending IdleTimeout shutdown, call Shutdown when done.
+func (hx711 *Hx711) Reset() error {
+	hx711.sessionMutex.Lock()
+	defer hx711.sessionMutex.Unlock()
+
+	hx711.stopIdleTimer()
+	return hx711.resetChip()
+}
+
+// Shutdown puts the chip in powered down mode.
+// The chip should be shutdown if it is not used for just about any amount of time.
+func (hx711 *Hx711) Shutdown() error {
+	hx711.sessionMutex.Lock()
+	defer hx711.sessionMutex.Unlock()
+
+	hx711.stopIdleTimer()
+	return hx711.shutdownChip()
+}
+
+// resetChip starts up or resets the chip and updates the chip state
+func (hx711 *Hx711) resetChip() error {
+	err := hx711.reset()
+	if err != nil {
+		return err
+	}
+	hx711.chipReset()
+	return nil
+}
+
+// shutdownChip puts the chip in powered down mode and updates the chip state
+func (hx711 *Hx711) shutdownChip() error {
+	hx711.poweredUp = false
+	return hx711.shutdown()
+}
+
+// stopIdleTimer stops a pending IdleTimeout shutdown. sessionMutex must be held.
+func (hx711 *Hx711) stopIdleTimer() {
+	if hx711.idleTimer != nil {
+		hx711.idleTimer.Stop()
+		hx711.idleTimer = nil
+	}
+	// a timer that already fired and is waiting on the lock will see the change and do nothing
+	hx711.idleGeneration++
+}
+
+// Acquire powers up the chip, if needed, and keeps it powered up until Release is called.
+// Reads done between Acquire and Release share one powered up period,
+// so there is no power up settling time between them.
+// Calls can be nested, the chip is shutdown after the last Release,
+// or IdleTimeout after the last Release if IdleTimeout is set.
+func (hx711 *Hx711) Acquire() error {
+	hx711.sessionMutex.Lock()
+	defer hx711.sessionMutex.Unlock()
 
-// SetGain can be set to gain of 128, 64, or 32.
-// Gain of 128 or 64 is input channel A, gain of 32 is input channel B.
-// Default gain is 128.
-// Note change only takes affect after one reading.
-func (hx711 *Hx711) SetGain(gain int) {
-	switch gain {
-	case 128:
-		hx711.numEndPulses = 1
-	case 64:
-		hx711.numEndPulses = 3
-	case 32:
-		hx711.numEndPulses = 2
-	default:
-		hx711.numEndPulses = 1
+	hx711.stopIdleTimer()
+
+	if hx711.sessions < 1 && !hx711.poweredUp {
+		err := hx711.resetChip()
+		if err != nil {
+			return fmt.Errorf("Reset error: %v", err)
+		}
 	}
+
+	hx711.sessions++
+	return nil
+}
+
+// Release ends a powered up period started with Acquire.
+// After the last Release the chip is shutdown, or if IdleTimeout is set, shutdown after IdleTimeout
+// unless Acquire is called again before then.
+func (hx711 *Hx711) Release() error {
+	hx711.sessionMutex.Lock()
+	defer hx711.sessionMutex.Unlock()
+
+	if hx711.sessions < 1 {
+		return fmt.Errorf("Release called without Acquire")
+	}
+	hx711.sessions--
+	if hx711.sessions > 0 {
+		return nil
+	}
+
+	if hx711.IdleTimeout > 0 {
+		hx711.idleGeneration++
+		generation := hx711.idleGeneration
+		hx711.idleTimer = time.AfterFunc(hx711.IdleTimeout, func() {
+			hx711.idleShutdown(generation)
+		})
+		return nil
+	}
+
+	return hx711.shutdownChip()
+}
+
+// idleShutdown shuts down the chip when the idle timer of generation fires,
+// unless Acquire has been called since the timer was started
+func (hx711 *Hx711) idleShutdown(generation uint64) {
+	hx711.sessionMutex.Lock()
+	defer hx711.sessionMutex.Unlock()
+
+	if hx711.sessions > 0 || hx711.idleGeneration != generation {
+		return
+	}
+	hx711.idleTimer = nil
+
+	err := hx711.shutdownChip()
+	if err != nil {
+		log.Print("hx711 idle Shutdown error:", err)
+	}
+}
+
+// chipReset updates the chip state after the chip has powered up or reset itself.
+// After a reset the chip is at gain of 128 and needs to settle.
+func (hx711 *Hx711) chipReset() {
+	hx711.poweredUp = true
+	hx711.activeEndPulses = 1
+	hx711.startSettling()
+}
+
+// startSettling starts the settling time, readings are thrown away until it has passed
+func (hx711 *Hx711) startSettling() {
+	hx711.settleUntil = time.Now().Add(hx711.settlingTime())
+}
+
+// startGainSettling starts the settling time after a gain or channel change.
+// Readings are thrown away until it has passed, except interleaved readings which switch channel every reading.
+func (hx711 *Hx711) startGainSettling() {
+	hx711.gainSettleUntil = time.Now().Add(hx711.settlingTime())
+}
+
+// ReadDataRaw will get one raw reading from chip.
+// Usually will need to call Reset before calling this and Shutdown after.
+// Readings where the clock pin was held high for longer than MaxClockHigh are corrupt,
+// those are counted and retried up to ReadRetries times.
+// Readings taken before the chip has settled after Reset, power down, rate change,
+// or gain change are thrown away.
+// Stops a pending IdleTimeout shutdown so it can not power down the chip during the reading.
+func (hx711 *Hx711) ReadDataRaw() (int, error) {
+	hx711.sessionMutex.Lock()
+	hx711.stopIdleTimer()
+	hx711.sessionMutex.Unlock()
+
+	return hx711.readData(hx711.numEndPulses, hx711.numEndPulses, true)
+}
+
+// ReadDataInterleavedRaw will get one raw reading from channel A and then one raw reading from channel B.
+// Channel A is at gain of 128, or 64 if SetGain(64) was called. Channel B is at gain of 32.
+// The chip switches channel every reading, the readings are not given settling time after each switch,
+// but the next ReadDataRaw throws away readings until the chip has settled at the requested gain.
+// Usually will need to call Reset before calling this and Shutdown after.
+func (hx711 *Hx711) ReadDataInterleavedRaw() (int, int, error) {
+	hx711.sessionMutex.Lock()
+	hx711.stopIdleTimer()
+	hx711.sessionMutex.Unlock()
+
+	channelA := hx711.channelAEndPulses()
+
+	dataA, err := hx711.readData(channelA, 2, false)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	dataB, err := hx711.readData(2, channelA, false)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return dataA, dataB, nil
+}
+
+// channelAEndPulses returns the number of end pulses for channel A based on the set gain
+func (hx711 *Hx711) channelAEndPulses() int {
+	if hx711.numEndPulses == 3 {
+		return 3
+	}
+	return 1
+}
+
+// readData will get one raw reading from chip that was converted with endPulses channel and gain.
+// nextEndPulses selects the channel and gain of the conversion after it.
+// The gain of a conversion is selected by the end pulses of the reading before it,
+// so readings at the wrong gain are thrown away.
+// Readings are thrown away until the chip has settled after Reset, power down, or rate change.
+// If waitGainSettling is true, readings are also thrown away until the chip has settled after a gain change.
+func (hx711 *Hx711) readData(endPulses int, nextEndPulses int, waitGainSettling bool) (int, error) {
+	if hx711.LockOSThread || hx711.RealTimePriority > 0 {
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+	}
+	if hx711.RealTimePriority > 0 {
+		restore, err := setRealTimePriority(hx711.RealTimePriority)
+		if err != nil {
+			return 0, fmt.Errorf("setRealTimePriority error: %v", err)
+		}
+		defer restore()
+	}
+
+	retries := 0
+	for {
+		if !hx711.poweredUp {
+			// the clock pin going low when waiting for data ready wakes up the chip
+			hx711.chipReset()
+		}
+		conversionEndPulses := hx711.activeEndPulses
+
+		// if this conversion is at the wrong gain, get the next conversion at the wanted gain
+		pulses := nextEndPulses
+		if conversionEndPulses != endPulses {
+			pulses = endPulses
+		}
+
+		data, clockHigh, err := hx711.readDataRaw(pulses)
+		if err != nil {
+			return 0, err
+		}
+
+		if hx711.MaxClockHigh > 0 && clockHigh > hx711.MaxClockHigh {
+			// chip powered down and reset itself during the reading so the data is garbage
+			hx711.timingViolations++
+			hx711.chipReset()
+			if retries >= hx711.ReadRetries {
+				return 0, ErrTimingViolation
+			}
+			retries++
+			continue
+		}
+
+		if hx711.activeEndPulses != pulses {
+			// the end pulses of this reading changed the gain for the next conversion
+			hx711.activeEndPulses = pulses
+			hx711.startGainSettling()
+		}
+
+		// reading was at a different gain or chip has not settled yet
+		now := time.Now()
+		if conversionEndPulses != endPulses || now.Before(hx711.settleUntil) ||
+			(waitGainSettling && now.Before(hx711.gainSettleUntil)) {
+			continue
+		}
+
+		return data, nil
+	}
+}
+
+// TimingViolations returns the number of readings that had the clock pin held high for too long.
+// These readings are corrupt and have been thrown away.
+func (hx711 *Hx711) TimingViolations() int {
+	return hx711.timingViolations
+}
+
+// SetRate sets the RATE pin for an output data rate of 10 or 80 samples per second.
+// Needs RatePinName set in the Config.
+// After a rate change the output takes 4 conversion periods to settle.
+func (hx711 *Hx711) SetRate(samplesPerSecond int) error {
+	err := checkSamplesPerSecond(samplesPerSecond)
+	if err != nil {
+		return err
+	}
+	err = hx711.setRatePin(samplesPerSecond == 80)
+	if err != nil {
+		return err
+	}
+	hx711.SamplesPerSecond = samplesPerSecond
+	hx711.startSettling()
+	return nil
+}
+
+// MeasureRate measures the output data rate of the chip in samples per second over numReadings readings.
+// Useful to confirm how the RATE pin is wired.
+// Do not call Reset before or Shutdown after.
+// Reset and Shutdown are called for you.
+func (hx711 *Hx711) MeasureRate(numReadings int) (float64, error) {
+	if numReadings < 1 {
+		return 0, fmt.Errorf("numReadings is less than 1")
+	}
+
+	err := hx711.Acquire()
+	if err != nil {
+		return 0, fmt.Errorf("Acquire error: %v", err)
+	}
+	defer hx711.Release()
+
+	// first reading is to line up with the start of a conversion
+	_, err = hx711.ReadDataRaw()
+	if err != nil {
+		return 0, fmt.Errorf("ReadDataRaw error: %v", err)
+	}
+
+	start := time.Now()
+	for i := 0; i < numReadings; i++ {
+		_, err = hx711.ReadDataRaw()
+		if err != nil {
+			return 0, fmt.Errorf("ReadDataRaw error: %v", err)
+		}
+	}
+
+	return float64(numReadings) / time.Since(start).Seconds(), nil
+}
+
+// VerifyRate measures the output data rate of the chip over numReadings readings
+// and returns an error if it does not match SamplesPerSecond.
+// Do not call Reset before or Shutdown after.
+// Reset and Shutdown are called for you.
+func (hx711 *Hx711) VerifyRate(numReadings int) error {
+	rate, err := hx711.MeasureRate(numReadings)
+	if err != nil {
+		return err
+	}
+
+	// half way between 10 and 80 on a log scale is about 28
+	measured := 10
+	if rate > 28 {
+		measured = 80
+	}
+	expected := int(time.Second / hx711.conversionPeriod())
+	if measured != expected {
+		return fmt.Errorf("measured %.1f samples per second but SamplesPerSecond is %v", rate, expected)
+	}
+
+	return nil
 }
 
 // readDataMedianRaw will get median of numReadings raw readings.
@@ -62,24 +383,27 @@ func (hx711 *Hx711) readDataMedianRaw(numReadings int, stop *bool) (int, error)
 // ReadDataMedianRaw will get median of numReadings raw readings.
 // Do not call Reset before or Shutdown after.
 // Reset and Shutdown are called for you.
+// Call between Acquire and Release to keep the chip powered up across calls.
 func (hx711 *Hx711) ReadDataMedianRaw(numReadings int) (int, error) {
 	var data int
 
-	err := hx711.Reset()
+	err := hx711.Acquire()
 	if err != nil {
-		return 0, fmt.Errorf("Reset error: %v", err)
+		return 0, fmt.Errorf("Acquire error: %v", err)
 	}
 
 	stop := false
 	data, err = hx711.readDataMedianRaw(numReadings, &stop)
 
-	hx711.Shutdown()
+	hx711.Release()
 
 	return data, err
 }
 
 // ReadDataMedian will get median of numReadings raw readings,
-// then will adjust number with AdjustZero and AdjustScale.
+// then will adjust number with AdjustZero and AdjustScale,
+// or the calibration for the gain if calibrations have been set with SetCalibration.
+// If LoadCompensation is set the number is corrected for creep and hysteresis.
 // Do not call Reset before or Shutdown after.
 // Reset and Shutdown are called for you.
 func (hx711 *Hx711) ReadDataMedian(numReadings int) (float64, error) {
@@ -87,7 +411,63 @@ func (hx711 *Hx711) ReadDataMedian(numReadings int) (float64, error) {
 	if err != nil {
 		return 0, err
 	}
-	return float64(data-hx711.AdjustZero) / hx711.AdjustScale, nil
+	_, gain := hx711.RequestedGain()
+	result, err := hx711.adjustData(data, gain, hx711.adjustCalibration())
+	if err != nil {
+		return 0, err
+	}
+	return hx711.compensateLoad(result), nil
+}
+
+// ReadDataInterleavedMedian will get median of numReadings interleaved raw readings of each channel,
+// then will adjust channel A with AdjustZero and AdjustScale and channel B with AdjustZeroB and AdjustScaleB.
+// If LoadCompensation is set channel A is corrected for creep and hysteresis.
+// Returns channel A then channel B.
+// Do not call Reset before or Shutdown after.
+// Reset and Shutdown are called for you.
+func (hx711 *Hx711) ReadDataInterleavedMedian(numReadings int) (float64, float64, error) {
+	err := hx711.Acquire()
+	if err != nil {
+		return 0, 0, fmt.Errorf("Acquire error: %v", err)
+	}
+	defer hx711.Release()
+
+	var dataA int
+	var dataB int
+	datasA := make([]int, 0, numReadings)
+	datasB := make([]int, 0, numReadings)
+
+	for i := 0; i < numReadings; i++ {
+		dataA, dataB, err = hx711.ReadDataInterleavedRaw()
+		if err != nil {
+			continue
+		}
+		// reading of -1 seems to be some kind of error
+		if dataA == -1 || dataB == -1 {
+			continue
+		}
+		datasA = append(datasA, dataA)
+		datasB = append(datasB, dataB)
+	}
+
+	if len(datasA) < 1 {
+		return 0, 0, fmt.Errorf("no data, last err: %v", err)
+	}
+
+	sort.Ints(datasA)
+	sort.Ints(datasB)
+
+	_, gainA := endPulsesChannelGain(hx711.channelAEndPulses())
+	adjustedA, err := hx711.adjustData(datasA[len(datasA)/2], gainA, hx711.adjustCalibration())
+	if err != nil {
+		return 0, 0, err
+	}
+	adjustedB, err := hx711.adjustData(datasB[len(datasB)/2], Gain32, hx711.adjustCalibrationB())
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return hx711.compensateChannelLoad(adjustedA, ChannelA), adjustedB, nil
 }
 
 // ReadDataMedianThenAvg will get median of numReadings raw readings,
@@ -95,16 +475,29 @@ func (hx711 *Hx711) ReadDataMedian(numReadings int) (float64, error) {
 // then will adjust number with AdjustZero and AdjustScale.
 // Do not call Reset before or Shutdown after.
 // Reset and Shutdown are called for you.
+// The chip stays powered up for all the readings.
 func (hx711 *Hx711) ReadDataMedianThenAvg(numReadings, numAvgs int) (float64, error) {
-	var sum int
+	err := hx711.Acquire()
+	if err != nil {
+		return 0, fmt.Errorf("Acquire error: %v", err)
+	}
+	defer hx711.Release()
+
+	_, gain := hx711.RequestedGain()
+	adjustZero, adjustScale, err := hx711.adjustValues(gain, hx711.adjustCalibration())
+	if err != nil {
+		return 0, err
+	}
+
+	var sum float64
 	for i := 0; i < numAvgs; i++ {
 		data, err := hx711.ReadDataMedianRaw(numReadings)
 		if err != nil {
 			return 0, err
 		}
-		sum += data - hx711.AdjustZero
+		sum += float64(data) - adjustZero
 	}
-	return (float64(sum) / float64(numAvgs)) / hx711.AdjustScale, nil
+	return hx711.compensateLoad((sum / float64(numAvgs)) / adjustScale), nil
 }
 
 // ReadDataMedianThenMovingAvgs will get median of numReadings raw readings,
@@ -136,6 +529,7 @@ func (hx711 *Hx711) ReadDataMedianThenMovingAvgs(numReadings, numAvgs int, previ
 // Will continue to get readings and update movingAvg until stop is set to true.
 // After it has been stopped, the stopped chan will be closed.
 // Note when scale errors the movingAvg value will not change.
+// Watchers added with AddWatcher are updated with each new movingAvg.
 // Do not call Reset before or Shutdown after.
 // Reset and Shutdown are called for you.
 // Will panic if movingAvg or stop are nil
@@ -146,22 +540,31 @@ func (hx711 *Hx711) BackgroundReadMovingAvgs(numReadings, numAvgs int, movingAvg
 	previousReadings := make([]float64, 0, numAvgs)
 
 	for {
-		err = hx711.Reset()
+		err = hx711.Acquire()
 		if err == nil {
 			break
 		}
-		log.Print("hx711 BackgroundReadMovingAvgs Reset error:", err)
+		log.Print("hx711 BackgroundReadMovingAvgs Acquire error:", err)
 		time.Sleep(time.Second)
 	}
 
 	for !*stop {
 		data, err = hx711.readDataMedianRaw(numReadings, stop)
-		if err != nil && err.Error() != "stopped" {
+		if err != nil {
+			if err.Error() == "stopped" {
+				break
+			}
 			log.Print("hx711 BackgroundReadMovingAvgs ReadDataMedian error:", err)
 			continue
 		}
 
-		result = float64(data-hx711.AdjustZero) / hx711.AdjustScale
+		_, gain := hx711.RequestedGain()
+		result, err = hx711.adjustData(data, gain, hx711.adjustCalibration())
+		if err != nil {
+			log.Print("hx711 BackgroundReadMovingAvgs adjustData error:", err)
+			continue
+		}
+		result = hx711.compensateLoad(result)
 		if len(previousReadings) < numAvgs {
 			previousReadings = append(previousReadings, result)
 		} else {
@@ -174,13 +577,193 @@ func (hx711 *Hx711) BackgroundReadMovingAvgs(numReadings, numAvgs int, movingAvg
 		}
 
 		*movingAvg = result / float64(len(previousReadings))
+
+		hx711.updateWatchers(*movingAvg, time.Now())
 	}
 
-	hx711.Shutdown()
+	hx711.Release()
 
 	close(stopped)
 }
 
+// BackgroundReadings it meant to be run in the background, run as a Goroutine.
+// Will continue to get median of numReadings raw readings, adjusted like ReadDataMedian,
+// and send them with the time to readings until stop is set to true.
+// Use numReadings of 1 to get every reading at the full rate of the chip.
+// Readings are dropped if readings chan is full, so use a buffered chan.
+// After it has been stopped, the stopped chan will be closed.
+// Do not call Reset before or Shutdown after.
+// Reset and Shutdown are called for you.
+// Will panic if stop is nil
+func (hx711 *Hx711) BackgroundReadings(numReadings int, readings chan<- Reading, stop *bool, stopped chan struct{}) {
+	var err error
+	var data int
+	var result float64
+
+	for {
+		err = hx711.Acquire()
+		if err == nil {
+			break
+		}
+		log.Print("hx711 BackgroundReadings Acquire error:", err)
+		time.Sleep(time.Second)
+	}
+
+	for !*stop {
+		data, err = hx711.readDataMedianRaw(numReadings, stop)
+		if err != nil {
+			if err.Error() == "stopped" {
+				break
+			}
+			log.Print("hx711 BackgroundReadings ReadDataMedian error:", err)
+			continue
+		}
+
+		_, gain := hx711.RequestedGain()
+		result, err = hx711.adjustData(data, gain, hx711.adjustCalibration())
+		if err != nil {
+			log.Print("hx711 BackgroundReadings adjustData error:", err)
+			continue
+		}
+		result = hx711.compensateLoad(result)
+
+		select {
+		case readings <- Reading{Time: time.Now(), Value: result}:
+		default:
+		}
+	}
+
+	hx711.Release()
+
+	close(stopped)
+}
+
+// Weigh will get median of numReadings raw readings, adjusted like ReadDataMedian, until stabilizer says they are stable
+// or timeout has passed, then returns a Weighing of the stable reading with Gross, Tare, and Net in DisplayUnit.
+// If the readings did not settle before timeout, the Weighing is of the last reading with Stable false.
+// Do not call Reset before or Shutdown after.
+// Reset and Shutdown are called for you.
+func (hx711 *Hx711) Weigh(numReadings int, stabilizer Stabilizer, timeout time.Duration) (Weighing, error) {
+	err := hx711.Acquire()
+	if err != nil {
+		return Weighing{}, fmt.Errorf("Acquire error: %v", err)
+	}
+	defer hx711.Release()
+
+	stable := Stabilizer{Tolerance: stabilizer.Tolerance, Duration: stabilizer.Duration}
+	deadline := time.Now().Add(timeout)
+	for {
+		value, err := hx711.ReadDataMedian(numReadings)
+		if err != nil {
+			return Weighing{}, fmt.Errorf("ReadDataMedian error: %v", err)
+		}
+		average, ok := stable.Update(Reading{Time: time.Now(), Value: value})
+		if ok {
+			return hx711.newWeighing(average, true)
+		}
+		if !time.Now().Before(deadline) {
+			return hx711.newWeighing(value, false)
+		}
+	}
+}
+
+// RunPeakHold it meant to be run in the background, run as a Goroutine.
+// Keeps the chip powered up and updates peakHold with every reading at the full rate of the chip,
+// adjusted with the calibration but without median filtering or LoadCompensation,
+// until stop is set to true.
+// After it has been stopped, the stopped chan will be closed.
+// Do not call Reset before or Shutdown after.
+// Reset and Shutdown are called for you.
+// Will panic if peakHold or stop are nil
+func (hx711 *Hx711) RunPeakHold(peakHold *PeakHold, stop *bool, stopped chan struct{}) {
+	var err error
+	var data int
+	var result float64
+
+	for {
+		err = hx711.Acquire()
+		if err == nil {
+			break
+		}
+		log.Print("hx711 RunPeakHold Acquire error:", err)
+		time.Sleep(time.Second)
+	}
+
+	for !*stop {
+		data, err = hx711.ReadDataRaw()
+		if err != nil {
+			log.Print("hx711 RunPeakHold ReadDataRaw error:", err)
+			continue
+		}
+		// reading of -1 seems to be some kind of error
+		if data == -1 {
+			continue
+		}
+
+		_, gain := hx711.RequestedGain()
+		result, err = hx711.adjustData(data, gain, hx711.adjustCalibration())
+		if err != nil {
+			log.Print("hx711 RunPeakHold adjustData error:", err)
+			continue
+		}
+
+		peakHold.Update(Reading{Time: time.Now(), Value: result})
+	}
+
+	hx711.Release()
+
+	close(stopped)
+}
+
+// RunCapture arms capture then keeps the chip powered up and passes it every reading
+// at the full rate of the chip, adjusted with the calibration but without median filtering or LoadCompensation,
+// until the capture is done or stop is set to true.
+// Do not call Reset before or Shutdown after.
+// Reset and Shutdown are called for you.
+// Returns an error if PreTrigger or PostTrigger is less than 0.
+// Will panic if capture or stop are nil
+func (hx711 *Hx711) RunCapture(capture *Capture, stop *bool) (CaptureResult, error) {
+	err := capture.validate()
+	if err != nil {
+		return CaptureResult{}, err
+	}
+
+	err = hx711.Acquire()
+	if err != nil {
+		return CaptureResult{}, fmt.Errorf("Acquire error: %v", err)
+	}
+	defer hx711.Release()
+
+	capture.Arm()
+	_, gain := hx711.RequestedGain()
+
+	var data int
+	var value float64
+	for !*stop {
+		data, err = hx711.ReadDataRaw()
+		if err != nil {
+			log.Print("hx711 RunCapture ReadDataRaw error:", err)
+			continue
+		}
+		// reading of -1 seems to be some kind of error
+		if data == -1 {
+			continue
+		}
+
+		value, err = hx711.adjustData(data, gain, hx711.adjustCalibration())
+		if err != nil {
+			return CaptureResult{}, err
+		}
+
+		result, done := capture.Update(Sample{Time: time.Now(), Raw: data, Value: value})
+		if done {
+			return result, nil
+		}
+	}
+
+	return CaptureResult{}, ErrCaptureStopped
+}
+
 // GetAdjustValues will help get you the adjust values to plug in later.
 // Do not call Reset before or Shutdown after.
 // Reset and Shutdown are called for you.
@@ -190,10 +773,13 @@ func (hx711 *Hx711) GetAdjustValues(weight1 float64, weight2 float64) {
 	var scale1 int
 	var scale2 int
 
+	// median over about 1.1 seconds of readings
+	numReadings := hx711.ReadingsFor(1100 * time.Millisecond)
+
 	fmt.Println("Make sure scale is working and empty, getting weight in 5 seconds...")
 	time.Sleep(5 * time.Second)
 	fmt.Println("Getting weight...")
-	adjustZero, err = hx711.ReadDataMedianRaw(11)
+	adjustZero, err = hx711.ReadDataMedianRaw(numReadings)
 	if err != nil {
 		fmt.Println("ReadDataMedianRaw error:", err)
 		return
@@ -204,7 +790,7 @@ func (hx711 *Hx711) GetAdjustValues(weight1 float64, weight2 float64) {
 	fmt.Printf("Put first weight of %.2f on scale, getting weight in 15 seconds...\n", weight1)
 	time.Sleep(15 * time.Second)
 	fmt.Println("Getting weight...")
-	scale1, err = hx711.ReadDataMedianRaw(11)
+	scale1, err = hx711.ReadDataMedianRaw(numReadings)
 	if err != nil {
 		fmt.Println("ReadDataMedianRaw error:", err)
 		return
@@ -215,7 +801,7 @@ func (hx711 *Hx711) GetAdjustValues(weight1 float64, weight2 float64) {
 	fmt.Printf("Put second weight of %.2f on scale, getting weight in 15 seconds...\n", weight2)
 	time.Sleep(15 * time.Second)
 	fmt.Println("Getting weight...")
-	scale2, err = hx711.ReadDataMedianRaw(11)
+	scale2, err = hx711.ReadDataMedianRaw(numReadings)
 	if err != nil {
 		fmt.Println("ReadDataMedianRaw error:", err)
 		return
@@ -226,6 +812,8 @@ func (hx711 *Hx711) GetAdjustValues(weight1 float64, weight2 float64) {
 	adjust1 := float64(scale1-adjustZero) / weight1
 	adjust2 := float64(scale2-adjustZero) / weight2
 
+	channel, gain := hx711.RequestedGain()
+	fmt.Printf("Adjust values are for channel %v with gain of %v\n", channel, int(gain))
 	fmt.Println("AdjustZero should be set to:", adjustZero)
 	fmt.Printf("AdjustScale should be set to a value between %f and %f\n", adjust1, adjust2)
 	fmt.Println("")
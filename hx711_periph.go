@@ -21,14 +21,28 @@ func HostInit() error {
 // Make sure to set clockPinName and dataPinName to the correct pins.
 // https://cdn.sparkfun.com/datasheets/Sensors/ForceFlex/hx711_english.pdf
 func NewHx711(clockPinName string, dataPinName string) (*Hx711, error) {
-	hx711 := &Hx711{numEndPulses: 1}
+	return NewHx711WithConfig(Config{ClockPinName: clockPinName, DataPinName: dataPinName})
+}
+
+// NewHx711WithConfig creates new Hx711 using config.
+// Make sure to set the pin names to the correct pins.
+// https://cdn.sparkfun.com/datasheets/Sensors/ForceFlex/hx711_english.pdf
+func NewHx711WithConfig(config Config) (*Hx711, error) {
+	if config.SamplesPerSecond != 0 {
+		err := checkSamplesPerSecond(config.SamplesPerSecond)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	hx711 := &Hx711{numEndPulses: 1, ReadRetries: 3, MaxClockHigh: defaultMaxClockHigh, SamplesPerSecond: defaultSamplesPerSecond}
 
-	hx711.clockPin = gpioreg.ByName(clockPinName)
+	hx711.clockPin = gpioreg.ByName(config.ClockPinName)
 	if hx711.clockPin == nil {
 		return nil, fmt.Errorf("clockPin is nill")
 	}
 
-	hx711.dataPin = gpioreg.ByName(dataPinName)
+	hx711.dataPin = gpioreg.ByName(config.DataPinName)
 	if hx711.dataPin == nil {
 		return nil, fmt.Errorf("dataPin is nill")
 	}
@@ -38,25 +52,60 @@ func NewHx711(clockPinName string, dataPinName string) (*Hx711, error) {
 		return nil, fmt.Errorf("dataPin setting to in error: %v", err)
 	}
 
+	if config.RatePinName != "" {
+		hx711.ratePin = gpioreg.ByName(config.RatePinName)
+		if hx711.ratePin == nil {
+			return nil, fmt.Errorf("ratePin is nill")
+		}
+	}
+
+	if config.SamplesPerSecond != 0 {
+		hx711.SamplesPerSecond = config.SamplesPerSecond
+	}
+	if hx711.ratePin != nil {
+		err = hx711.SetRate(hx711.SamplesPerSecond)
+		if err != nil {
+			return nil, fmt.Errorf("SetRate error: %v", err)
+		}
+	}
+
 	return hx711, nil
 }
 
-// setClockHighThenLow sets clock pin high then low
-func (hx711 *Hx711) setClockHighThenLow() error {
+// setRatePin sets the RATE pin, high for 80 samples per second and low for 10
+func (hx711 *Hx711) setRatePin(high bool) error {
+	if hx711.ratePin == nil {
+		return ErrNoRatePin
+	}
+	level := gpio.Low
+	if high {
+		level = gpio.High
+	}
+	err := hx711.ratePin.Out(level)
+	if err != nil {
+		return fmt.Errorf("set rate pin error: %v", err)
+	}
+	return nil
+}
+
+// setClockHighThenLow sets clock pin high then low.
+// Returns an upper bound of how long the clock pin was high.
+func (hx711 *Hx711) setClockHighThenLow() (time.Duration, error) {
+	// start before setting high so time spent returning from setting high is counted
+	start := time.Now()
 	err := hx711.clockPin.Out(gpio.High)
 	if err != nil {
-		return fmt.Errorf("set clock pin to high error: %v", err)
+		return 0, fmt.Errorf("set clock pin to high error: %v", err)
 	}
 	err = hx711.clockPin.Out(gpio.Low)
 	if err != nil {
-		return fmt.Errorf("set clock pin to low error: %v", err)
+		return 0, fmt.Errorf("set clock pin to low error: %v", err)
 	}
-	return nil
+	return time.Since(start), nil
 }
 
-// Reset starts up or resets the chip.
-// The chip needs to be reset if it is not used for just about any amount of time.
-func (hx711 *Hx711) Reset() error {
+// reset starts up or resets the chip
+func (hx711 *Hx711) reset() error {
 	err := hx711.clockPin.Out(gpio.Low)
 	if err != nil {
 		return fmt.Errorf("set clock pin to low error: %v", err)
@@ -73,9 +122,8 @@ func (hx711 *Hx711) Reset() error {
 	return nil
 }
 
-// Shutdown puts the chip in powered down mode.
-// The chip should be shutdown if it is not used for just about any amount of time.
-func (hx711 *Hx711) Shutdown() error {
+// shutdown puts the chip in powered down mode
+func (hx711 *Hx711) shutdown() error {
 	err := hx711.clockPin.Out(gpio.High)
 	if err != nil {
 		return fmt.Errorf("set clock pin to high error: %v", err)
@@ -83,44 +131,66 @@ func (hx711 *Hx711) Shutdown() error {
 	return nil
 }
 
-// waitForDataReady waits for data to go to low which means chip is ready
+// waitForDataReady waits for data to go to low which means chip is ready.
+// Waits up to readyTimeout using the ReadyWait strategy.
 func (hx711 *Hx711) waitForDataReady() error {
 	err := hx711.clockPin.Out(gpio.Low)
 	if err != nil {
 		return fmt.Errorf("set clock pin to low error: %v", err)
 	}
 
-	var level gpio.Level
+	timeout := hx711.readyTimeout()
+	deadline := time.Now().Add(timeout)
+	pollInterval := hx711.readyPollInterval()
+	edgeWait := hx711.conversionPeriod()
 
-	// looks like chip often takes 80 to 100 milliseconds to get ready
-	// but somettimes it takes around 500 milliseconds to get ready
 	// WaitForEdge sometimes returns right away
-	// So will loop for 11, which could be more than 1 second, but usually 500 milliseconds
-	for i := 0; i < 11; i++ {
-		level = hx711.dataPin.Read()
-		if level == gpio.Low {
+	// So will loop and check level until timeout
+	for {
+		if hx711.dataPin.Read() == gpio.Low {
 			return nil
 		}
-		hx711.dataPin.WaitForEdge(100 * time.Millisecond)
-	}
 
-	return ErrTimeout
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return ErrTimeout
+		}
+
+		switch hx711.ReadyWait {
+		case WaitPoll:
+			if pollInterval < remaining {
+				remaining = pollInterval
+			}
+			time.Sleep(remaining)
+		default:
+			if edgeWait < remaining {
+				remaining = edgeWait
+			}
+			hx711.dataPin.WaitForEdge(remaining)
+		}
+	}
 }
 
-// ReadDataRaw will get one raw reading from chip.
-// Usually will need to call Reset before calling this and Shutdown after.
-func (hx711 *Hx711) ReadDataRaw() (int, error) {
+// readDataRaw will get one raw reading from chip, then send endPulses clock pulses
+// to select the channel and gain of the next conversion.
+// Also returns the longest time the clock pin was high during the reading.
+func (hx711 *Hx711) readDataRaw(endPulses int) (int, time.Duration, error) {
 	err := hx711.waitForDataReady()
 	if err != nil {
-		return 0, fmt.Errorf("waitForDataReady error: %v", err)
+		return 0, 0, fmt.Errorf("waitForDataReady error: %v", err)
 	}
 
 	var level gpio.Level
 	var data int
+	var clockHigh time.Duration
+	var maxClockHigh time.Duration
 	for i := 0; i < 24; i++ {
-		err = hx711.setClockHighThenLow()
+		clockHigh, err = hx711.setClockHighThenLow()
 		if err != nil {
-			return 0, fmt.Errorf("setClockHighThenLow error: %v", err)
+			return 0, 0, fmt.Errorf("setClockHighThenLow error: %v", err)
+		}
+		if clockHigh > maxClockHigh {
+			maxClockHigh = clockHigh
 		}
 
 		level = hx711.dataPin.Read()
@@ -130,10 +200,13 @@ func (hx711 *Hx711) ReadDataRaw() (int, error) {
 		}
 	}
 
-	for i := 0; i < hx711.numEndPulses; i++ {
-		err = hx711.setClockHighThenLow()
+	for i := 0; i < endPulses; i++ {
+		clockHigh, err = hx711.setClockHighThenLow()
 		if err != nil {
-			return 0, fmt.Errorf("setClockHighThenLow error: %v", err)
+			return 0, 0, fmt.Errorf("setClockHighThenLow error: %v", err)
+		}
+		if clockHigh > maxClockHigh {
+			maxClockHigh = clockHigh
 		}
 	}
 
@@ -145,5 +218,68 @@ func (hx711 *Hx711) ReadDataRaw() (int, error) {
 		data |= ^0xffffff
 	}
 
-	return data, nil
+	return data, maxClockHigh, nil
+}
+
+// gpioOutput is an Output using a GPIO pin
+type gpioOutput struct {
+	pin gpio.PinIO
+}
+
+// NewGPIOOutput creates an Output, such as for FillController, that sets the pin high for on
+func NewGPIOOutput(pinName string) (Output, error) {
+	pin := gpioreg.ByName(pinName)
+	if pin == nil {
+		return nil, fmt.Errorf("pin is nill")
+	}
+	err := pin.Out(gpio.Low)
+	if err != nil {
+		return nil, fmt.Errorf("set pin to low error: %v", err)
+	}
+	return &gpioOutput{pin: pin}, nil
+}
+
+// Set sets the pin high for on and low for off
+func (output *gpioOutput) Set(on bool) error {
+	level := gpio.Low
+	if on {
+		level = gpio.High
+	}
+	return output.pin.Out(level)
+}
+
+// NewGPIOPulseCounter creates a PulseCounter, such as for BeltScale, that counts rising edges on the pin.
+// Call Close to stop counting.
+func NewGPIOPulseCounter(pinName string, distancePerPulse float64) (*PulseCounter, error) {
+	pin := gpioreg.ByName(pinName)
+	if pin == nil {
+		return nil, fmt.Errorf("pin is nill")
+	}
+	err := pin.In(gpio.PullUp, gpio.RisingEdge)
+	if err != nil {
+		return nil, fmt.Errorf("set pin to in error: %v", err)
+	}
+
+	pulseCounter := &PulseCounter{
+		DistancePerPulse: distancePerPulse,
+		stop:             make(chan struct{}),
+		stopped:          make(chan struct{}),
+	}
+
+	go func() {
+		defer close(pulseCounter.stopped)
+		for {
+			select {
+			case <-pulseCounter.stop:
+				pin.In(gpio.PullUp, gpio.NoEdge)
+				return
+			default:
+			}
+			if pin.WaitForEdge(200 * time.Millisecond) {
+				pulseCounter.Pulse(time.Now())
+			}
+		}
+	}()
+
+	return pulseCounter, nil
 }
@@ -0,0 +1,497 @@
+// +build !windows,!gpiomem
+
+package hx711
+
+import (
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"periph.io/x/periph/conn/gpio"
+	"periph.io/x/periph/conn/gpio/gpiotest"
+)
+
+const (
+	// fakeUnsettled is the reading of the fake chip before it has settled after a reset or gain change
+	fakeUnsettled = 0x123456
+	// fakeSamplesPerSecond is the output data rate of the fake chip
+	fakeSamplesPerSecond = 80
+)
+
+// fakeHx711 simulates the chip behind a clock and data gpiotest.Pin.
+// Conversions take a conversion period, the end pulses of a reading select the gain of the next conversion,
+// the clock pin held high for over 60 microseconds powers down the chip, and it resets when the clock goes low.
+// Readings are fakeUnsettled for settlePeriods conversion periods after a reset or gain change.
+type fakeHx711 struct {
+	clock *fakeClockPin
+	data  *fakeDataPin
+
+	mutex  sync.Mutex
+	period time.Duration
+	// values is the settled reading at each gain
+	values map[Gain]int
+	// settlePeriods is the number of conversion periods readings are fakeUnsettled for
+	// after a reset or gain change
+	settlePeriods int
+	// violations is the number of next readings to hold the clock pin high too long during
+	violations int
+	// resets is the number of times the chip has powered up or reset
+	resets int
+
+	reading       bool
+	bits          int
+	conversion    int
+	endPulses     int
+	countingEnd   bool
+	gainEndPulses int
+	selectedAt    time.Time
+	lastEndPulses int
+	settleFrom    time.Time
+	readyAt       time.Time
+	high          bool
+	highSince     time.Time
+}
+
+// fakeClockPin is the clock pin of a fakeHx711
+type fakeClockPin struct {
+	gpiotest.Pin
+	chip *fakeHx711
+}
+
+// fakeDataPin is the data pin of a fakeHx711
+type fakeDataPin struct {
+	gpiotest.Pin
+	chip *fakeHx711
+}
+
+// newFakeHx711 returns a powered up fake chip at gain of 128 with settled readings of values
+func newFakeHx711(values map[Gain]int) *fakeHx711 {
+	chip := &fakeHx711{period: time.Second / fakeSamplesPerSecond, values: values, settlePeriods: 3}
+	chip.clock = &fakeClockPin{Pin: gpiotest.Pin{N: "CLK"}, chip: chip}
+	chip.data = &fakeDataPin{Pin: gpiotest.Pin{N: "DOUT"}, chip: chip}
+	chip.powerUp(time.Now())
+	return chip
+}
+
+// newTestHx711 returns an Hx711 with the defaults of NewHx711WithConfig using the pins of chip,
+// except more ReadRetries so real timing violations on a busy test machine do not fail readings
+func newTestHx711(chip *fakeHx711) *Hx711 {
+	return &Hx711{
+		clockPin:         chip.clock,
+		dataPin:          chip.data,
+		numEndPulses:     1,
+		ReadRetries:      10,
+		MaxClockHigh:     defaultMaxClockHigh,
+		SamplesPerSecond: fakeSamplesPerSecond,
+		ReadyWait:        WaitPoll,
+	}
+}
+
+// powerUp resets the chip to gain of 128 and starts a conversion
+func (chip *fakeHx711) powerUp(now time.Time) {
+	chip.resets++
+	chip.reading = false
+	chip.countingEnd = false
+	chip.gainEndPulses = 1
+	chip.lastEndPulses = 1
+	chip.settleFrom = now
+	chip.readyAt = now.Add(chip.period)
+}
+
+// SetViolations holds the clock pin high too long during each of the next violations readings
+func (chip *fakeHx711) SetViolations(violations int) {
+	chip.mutex.Lock()
+	chip.violations = violations
+	chip.mutex.Unlock()
+}
+
+// Out sets the clock pin and clocks the chip
+func (pin *fakeClockPin) Out(level gpio.Level) error {
+	chip := pin.chip
+	chip.mutex.Lock()
+	defer chip.mutex.Unlock()
+
+	now := time.Now()
+	if level == gpio.Low {
+		if chip.high && now.Sub(chip.highSince) > defaultMaxClockHigh {
+			chip.powerUp(now)
+		}
+		chip.high = false
+		return pin.Pin.Out(level)
+	}
+	if chip.high {
+		return pin.Pin.Out(level)
+	}
+	chip.high = true
+	chip.highSince = now
+
+	switch {
+	case chip.reading && chip.bits < 24:
+		chip.bits++
+		if chip.bits == 12 && chip.violations > 0 {
+			// goroutine descheduled with the clock pin high
+			chip.violations--
+			time.Sleep(2 * defaultMaxClockHigh)
+		}
+	case chip.reading:
+		// 25th pulse ends the reading and starts the next conversion
+		chip.reading = false
+		chip.countingEnd = true
+		chip.endPulses = 1
+		chip.gainEndPulses = 1
+		chip.selectedAt = now
+		chip.readyAt = now.Add(chip.period)
+	case !now.Before(chip.readyAt):
+		chip.reading = true
+		chip.countingEnd = false
+		chip.bits = 1
+		if chip.gainEndPulses != chip.lastEndPulses {
+			chip.lastEndPulses = chip.gainEndPulses
+			chip.settleFrom = chip.selectedAt
+		}
+		_, gain := endPulsesChannelGain(chip.gainEndPulses)
+		chip.conversion = chip.values[gain]
+		if now.Before(chip.settleFrom.Add(time.Duration(chip.settlePeriods) * chip.period)) {
+			chip.conversion = fakeUnsettled
+		}
+	case chip.countingEnd && chip.endPulses < 3:
+		chip.endPulses++
+		chip.gainEndPulses = chip.endPulses
+		chip.selectedAt = now
+	}
+
+	return pin.Pin.Out(level)
+}
+
+// Read returns the data pin, low when a conversion is ready then the bits of the reading
+func (pin *fakeDataPin) Read() gpio.Level {
+	chip := pin.chip
+	chip.mutex.Lock()
+	defer chip.mutex.Unlock()
+
+	if chip.reading {
+		if (chip.conversion>>uint(24-chip.bits))&1 == 1 {
+			return gpio.High
+		}
+		return gpio.Low
+	}
+	if chip.high || time.Now().Before(chip.readyAt) {
+		return gpio.High
+	}
+	return gpio.Low
+}
+
+func TestReadDataRawTimingViolations(t *testing.T) {
+	tests := []struct {
+		name           string
+		readRetries    int
+		maxClockHigh   time.Duration
+		violations     int
+		wantErr        error
+		wantViolations int
+	}{
+		{name: "retried", readRetries: 3, maxClockHigh: defaultMaxClockHigh, violations: 2, wantViolations: 2},
+		{name: "retries run out", readRetries: 1, maxClockHigh: defaultMaxClockHigh, violations: 2, wantErr: ErrTimingViolation, wantViolations: 2},
+		{name: "no retries", readRetries: 0, maxClockHigh: defaultMaxClockHigh, violations: 1, wantErr: ErrTimingViolation, wantViolations: 1},
+		{name: "check off", readRetries: 3, maxClockHigh: 0, violations: 1, wantViolations: 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			chip := newFakeHx711(map[Gain]int{Gain128: 100000})
+			hx711 := newTestHx711(chip)
+
+			err := hx711.Reset()
+			if err != nil {
+				t.Fatalf("Reset error: %v", err)
+			}
+			_, err = hx711.ReadDataRaw()
+			if err != nil {
+				t.Fatalf("ReadDataRaw error: %v", err)
+			}
+			violations := hx711.TimingViolations()
+			hx711.ReadRetries = test.readRetries
+			hx711.MaxClockHigh = test.maxClockHigh
+
+			chip.SetViolations(test.violations)
+			data, err := hx711.ReadDataRaw()
+			if err != test.wantErr {
+				t.Fatalf("ReadDataRaw error got %v want %v", err, test.wantErr)
+			}
+
+			// a busy test machine can add real timing violations
+			got := hx711.TimingViolations() - violations
+			if got < test.wantViolations || (test.maxClockHigh == 0 && got != 0) {
+				t.Errorf("TimingViolations got %v want %v", got, test.wantViolations)
+			}
+			if err != nil {
+				return
+			}
+			if test.maxClockHigh == 0 {
+				// without the check the reading the chip powered down during is garbage
+				if data == 100000 {
+					t.Errorf("ReadDataRaw got %v want garbage", data)
+				}
+				return
+			}
+			if data != 100000 {
+				t.Errorf("ReadDataRaw got %v want 100000", data)
+			}
+		})
+	}
+}
+
+func TestReadDataRawAfterInterleaved(t *testing.T) {
+	chip := newFakeHx711(map[Gain]int{Gain128: 100000, Gain32: -25000})
+	hx711 := newTestHx711(chip)
+
+	err := hx711.Reset()
+	if err != nil {
+		t.Fatalf("Reset error: %v", err)
+	}
+	data, err := hx711.ReadDataRaw()
+	if err != nil {
+		t.Fatalf("ReadDataRaw error: %v", err)
+	}
+	if data != 100000 {
+		t.Fatalf("ReadDataRaw got %v want 100000", data)
+	}
+
+	// interleaved readings are not given settling time, so channel B is still settling
+	violations := hx711.TimingViolations()
+	dataA, _, err := hx711.ReadDataInterleavedRaw()
+	if err != nil {
+		t.Fatalf("ReadDataInterleavedRaw error: %v", err)
+	}
+	if hx711.TimingViolations() != violations {
+		// the chip state is not known after a real timing violation and interleaved readings do not settle
+		t.Skip("timing violation during interleaved reading")
+	}
+	if dataA != 100000 {
+		t.Errorf("ReadDataInterleavedRaw channel A got %v want 100000", dataA)
+	}
+
+	// the switch back to channel A has to settle before ReadDataRaw returns a reading
+	for i := 0; i < 3; i++ {
+		data, err = hx711.ReadDataRaw()
+		if err != nil {
+			t.Fatalf("ReadDataRaw error: %v", err)
+		}
+		if data != 100000 {
+			t.Errorf("ReadDataRaw %v got %v want 100000", i, data)
+		}
+	}
+}
+
+func TestReadDataRawSettling(t *testing.T) {
+	values := map[Gain]int{Gain128: 100000, Gain64: 50000, Gain32: -25000}
+
+	tests := []struct {
+		name string
+		// change is done after Reset and one settled reading at gain of 128
+		change      func(hx711 *Hx711) error
+		want        int
+		wantGain    Gain
+		wantSettled bool
+	}{
+		{
+			name:   "reset",
+			change: func(hx711 *Hx711) error { return hx711.Reset() },
+			want:   100000, wantGain: Gain128, wantSettled: true,
+		},
+		{
+			name:   "gain of 64",
+			change: func(hx711 *Hx711) error { return hx711.SetGain(64) },
+			want:   50000, wantGain: Gain64, wantSettled: true,
+		},
+		{
+			name:   "channel B",
+			change: func(hx711 *Hx711) error { return hx711.SetChannelGain(ChannelB, Gain32) },
+			want:   -25000, wantGain: Gain32, wantSettled: true,
+		},
+		{
+			name:   "no change",
+			change: func(hx711 *Hx711) error { return nil },
+			want:   100000, wantGain: Gain128,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			chip := newFakeHx711(values)
+			hx711 := newTestHx711(chip)
+			err := hx711.Reset()
+			if err != nil {
+				t.Fatalf("Reset error: %v", err)
+			}
+			_, err = hx711.ReadDataRaw()
+			if err != nil {
+				t.Fatalf("ReadDataRaw error: %v", err)
+			}
+
+			err = test.change(hx711)
+			if err != nil {
+				t.Fatalf("change error: %v", err)
+			}
+			violations := hx711.TimingViolations()
+			start := time.Now()
+			data, err := hx711.ReadDataRaw()
+			if err != nil {
+				t.Fatalf("ReadDataRaw error: %v", err)
+			}
+			elapsed := time.Since(start)
+
+			// readings at the wrong gain and unsettled readings are thrown away
+			if data != test.want {
+				t.Errorf("ReadDataRaw got %v want %v", data, test.want)
+			}
+			if test.wantSettled && elapsed < hx711.settlingTime() {
+				t.Errorf("ReadDataRaw took %v want at least settling time of %v", elapsed, hx711.settlingTime())
+			}
+			// a real timing violation on a busy test machine resets the chip, which has to settle again
+			if !test.wantSettled && hx711.TimingViolations() == violations && elapsed >= hx711.settlingTime() {
+				t.Errorf("ReadDataRaw took %v want less than settling time of %v", elapsed, hx711.settlingTime())
+			}
+			_, gain := hx711.ActiveGain()
+			if gain != test.wantGain {
+				t.Errorf("ActiveGain got %v want %v", gain, test.wantGain)
+			}
+		})
+	}
+}
+
+func TestReadDataRawTimingViolationResetsGain(t *testing.T) {
+	chip := newFakeHx711(map[Gain]int{Gain128: 100000, Gain64: 50000})
+	hx711 := newTestHx711(chip)
+	err := hx711.SetGain(64)
+	if err != nil {
+		t.Fatalf("SetGain error: %v", err)
+	}
+	err = hx711.Reset()
+	if err != nil {
+		t.Fatalf("Reset error: %v", err)
+	}
+	data, err := hx711.ReadDataRaw()
+	if err != nil {
+		t.Fatalf("ReadDataRaw error: %v", err)
+	}
+	if data != 50000 {
+		t.Fatalf("ReadDataRaw got %v want 50000", data)
+	}
+
+	// the chip resets to gain of 128 when it powers down during a reading,
+	// so the retry has to change the gain back and settle again
+	chip.SetViolations(1)
+	data, err = hx711.ReadDataRaw()
+	if err != nil {
+		t.Fatalf("ReadDataRaw error: %v", err)
+	}
+	if data != 50000 {
+		t.Errorf("ReadDataRaw got %v want 50000", data)
+	}
+	if hx711.TimingViolations() < 1 {
+		t.Errorf("TimingViolations got %v want at least 1", hx711.TimingViolations())
+	}
+}
+
+// Resets returns the number of times the chip has powered up or reset
+func (chip *fakeHx711) Resets() int {
+	chip.mutex.Lock()
+	defer chip.mutex.Unlock()
+	return chip.resets
+}
+
+func TestIdleTimeout(t *testing.T) {
+	chip := newFakeHx711(map[Gain]int{Gain128: 100000})
+	hx711 := newTestHx711(chip)
+	hx711.IdleTimeout = 30 * time.Millisecond
+
+	data, err := hx711.ReadDataMedianRaw(1)
+	if err != nil {
+		t.Fatalf("ReadDataMedianRaw error: %v", err)
+	}
+	if data != 100000 {
+		t.Fatalf("ReadDataMedianRaw got %v want 100000", data)
+	}
+	time.Sleep(2 * hx711.IdleTimeout)
+	if chip.clock.Read() != gpio.High {
+		t.Fatal("chip not shutdown after IdleTimeout")
+	}
+
+	// Reset then reading with ReadDataRaw while the idle timer is pending
+	_, err = hx711.ReadDataMedianRaw(1)
+	if err != nil {
+		t.Fatalf("ReadDataMedianRaw error: %v", err)
+	}
+	err = hx711.Reset()
+	if err != nil {
+		t.Fatalf("Reset error: %v", err)
+	}
+	start := time.Now()
+	for time.Since(start) < 2*hx711.IdleTimeout {
+		data, err = hx711.ReadDataRaw()
+		if err != nil {
+			t.Fatalf("ReadDataRaw error: %v", err)
+		}
+		if data != 100000 {
+			t.Fatalf("ReadDataRaw got %v want 100000", data)
+		}
+	}
+	time.Sleep(2 * hx711.IdleTimeout)
+	if chip.clock.Read() != gpio.Low {
+		t.Error("idle timer shutdown chip after Reset")
+	}
+
+	err = hx711.Shutdown()
+	if err != nil {
+		t.Fatalf("Shutdown error: %v", err)
+	}
+	time.Sleep(time.Millisecond)
+	// the clock pin going low powers up the chip again
+	resets := chip.Resets()
+	data, err = hx711.ReadDataRaw()
+	if err != nil {
+		t.Fatalf("ReadDataRaw error: %v", err)
+	}
+	if data != 100000 {
+		t.Errorf("ReadDataRaw after Shutdown got %v want 100000", data)
+	}
+	if chip.Resets() <= resets {
+		t.Error("chip did not power up after Shutdown")
+	}
+}
+
+func TestReadDataInterleavedMedianLoadCompensation(t *testing.T) {
+	chip := newFakeHx711(map[Gain]int{Gain128: 100000, Gain32: -25000})
+	// interleaved readings switch channel every reading, so are never settled on a real chip
+	chip.settlePeriods = 0
+	hx711 := newTestHx711(chip)
+	hx711.AdjustScale = 1000
+	hx711.AdjustScaleB = 100
+	// creep of 1 that does not change without a CreepTimeConstant
+	hx711.LoadCompensation = &LoadCompensation{StepThreshold: 1, creep: 1}
+
+	dataA, dataB, err := hx711.ReadDataInterleavedMedian(3)
+	if err != nil {
+		t.Fatalf("ReadDataInterleavedMedian error: %v", err)
+	}
+	if math.Abs(dataA-99) > 1e-9 {
+		t.Errorf("channel A got %v want 99", dataA)
+	}
+	if math.Abs(dataB-(-250)) > 1e-9 {
+		t.Errorf("channel B got %v want -250", dataB)
+	}
+}
+
+func TestRunCaptureNegativePreTrigger(t *testing.T) {
+	chip := newFakeHx711(map[Gain]int{Gain128: 100000})
+	hx711 := newTestHx711(chip)
+	hx711.AdjustScale = 1
+
+	stop := false
+	_, err := hx711.RunCapture(&Capture{PreTrigger: -1, Level: 1}, &stop)
+	if err == nil {
+		t.Error("RunCapture PreTrigger less than 0 expected error")
+	}
+}
@@ -21,32 +21,84 @@ func HostInit() error {
 // https://cdn.sparkfun.com/datasheets/Sensors/ForceFlex/hx711_english.pdf
 // https://godoc.org/github.com/stianeikeland/go-rpio#Pin
 func NewHx711(clockPinName string, dataPinName string) (*Hx711, error) {
-	clockPin, err := strconv.ParseInt(clockPinName, 10, 32)
+	return NewHx711WithConfig(Config{ClockPinName: clockPinName, DataPinName: dataPinName})
+}
+
+// NewHx711WithConfig creates new Hx711 using config.
+// Make sure to set the pin names to the correct pins.
+// The pin numbers must comply with BCM numbering schema.
+// https://cdn.sparkfun.com/datasheets/Sensors/ForceFlex/hx711_english.pdf
+// https://godoc.org/github.com/stianeikeland/go-rpio#Pin
+func NewHx711WithConfig(config Config) (*Hx711, error) {
+	if config.SamplesPerSecond != 0 {
+		err := checkSamplesPerSecond(config.SamplesPerSecond)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	clockPin, err := strconv.ParseInt(config.ClockPinName, 10, 32)
 	if err != nil {
 		return nil, err
 	}
-	dataPin, err := strconv.ParseInt(dataPinName, 10, 32)
+	dataPin, err := strconv.ParseInt(config.DataPinName, 10, 32)
 	if err != nil {
 		return nil, err
 	}
-	hx711 := &Hx711{numEndPulses: 1}
+	hx711 := &Hx711{numEndPulses: 1, ReadRetries: 3, MaxClockHigh: defaultMaxClockHigh, SamplesPerSecond: defaultSamplesPerSecond}
 	hx711.clockPin = rpio.Pin(int(clockPin))
 	hx711.dataPin = rpio.Pin(int(dataPin))
 	hx711.dataPin.Input()
 	hx711.clockPin.Output()
+
+	if config.RatePinName != "" {
+		ratePin, err := strconv.ParseInt(config.RatePinName, 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		hx711.ratePin = rpio.Pin(int(ratePin))
+		hx711.hasRatePin = true
+		hx711.ratePin.Output()
+	}
+
+	if config.SamplesPerSecond != 0 {
+		hx711.SamplesPerSecond = config.SamplesPerSecond
+	}
+	if hx711.hasRatePin {
+		err = hx711.SetRate(hx711.SamplesPerSecond)
+		if err != nil {
+			return nil, fmt.Errorf("SetRate error: %v", err)
+		}
+	}
+
 	return hx711, nil
 }
 
-// setClockHighThenLow sets clock pin high then low
-func (hx711 *Hx711) setClockHighThenLow() error {
+// setRatePin sets the RATE pin, high for 80 samples per second and low for 10
+func (hx711 *Hx711) setRatePin(high bool) error {
+	if !hx711.hasRatePin {
+		return ErrNoRatePin
+	}
+	if high {
+		hx711.ratePin.Write(rpio.High)
+	} else {
+		hx711.ratePin.Write(rpio.Low)
+	}
+	return nil
+}
+
+// setClockHighThenLow sets clock pin high then low.
+// Returns an upper bound of how long the clock pin was high.
+func (hx711 *Hx711) setClockHighThenLow() (time.Duration, error) {
+	// start before setting high so time spent returning from setting high is counted
+	start := time.Now()
 	hx711.clockPin.Write(rpio.High)
 	hx711.clockPin.Write(rpio.Low)
-	return nil
+	return time.Since(start), nil
 }
 
-// Reset starts up or resets the chip.
-// The chip needs to be reset if it is not used for just about any amount of time.
-func (hx711 *Hx711) Reset() error {
+// reset starts up or resets the chip
+func (hx711 *Hx711) reset() error {
 	hx711.clockPin.Write(rpio.Low)
 	hx711.clockPin.Write(rpio.High)
 	time.Sleep(70 * time.Microsecond)
@@ -54,56 +106,68 @@ func (hx711 *Hx711) Reset() error {
 	return nil
 }
 
-// Shutdown puts the chip in powered down mode.
-// The chip should be shutdown if it is not used for just about any amount of time.
-func (hx711 *Hx711) Shutdown() error {
+// shutdown puts the chip in powered down mode
+func (hx711 *Hx711) shutdown() error {
 	hx711.clockPin.Write(rpio.High)
 	return nil
 }
 
-// waitForDataReady waits for data to go to low which means chip is ready
+// waitForDataReady waits for data to go to low which means chip is ready.
+// Waits up to readyTimeout using the ReadyWait strategy.
 func (hx711 *Hx711) waitForDataReady() error {
 	var level rpio.State
 
-	hx711.dataPin.Detect(rpio.FallEdge)
+	edge := hx711.ReadyWait != WaitPoll
+	if edge {
+		hx711.dataPin.Detect(rpio.FallEdge)
+		defer hx711.dataPin.Detect(rpio.NoEdge)
+	}
 	hx711.clockPin.Write(rpio.Low)
-	defer hx711.dataPin.Detect(rpio.NoEdge)
 	level = hx711.dataPin.Read()
 	if level == rpio.Low {
 		return nil
 	}
-	const (
-		// since there's no way to intercept the edge without using sysfs and epoll, we need to
-		// read the GPIO memory bit multiple times until the edge presence is detected.
-		EDGE_TRY_LOOP = 1500
-
-		// delay between reading attempts
-		BUSY_LOOP_DELAY = 250 * time.Microsecond
-	)
-	for i := 0; i < EDGE_TRY_LOOP; i++ {
-		if hx711.dataPin.EdgeDetected() {
-			return nil
+
+	// since there's no way to intercept the edge without using sysfs and epoll, we need to
+	// read the GPIO memory bit multiple times until the edge presence or low level is detected.
+	deadline := time.Now().Add(hx711.readyTimeout())
+	pollInterval := hx711.readyPollInterval()
+	for time.Now().Before(deadline) {
+		if edge {
+			if hx711.dataPin.EdgeDetected() {
+				return nil
+			}
+		} else {
+			if hx711.dataPin.Read() == rpio.Low {
+				return nil
+			}
 		}
-		time.Sleep(BUSY_LOOP_DELAY)
+		time.Sleep(pollInterval)
 	}
 
 	return ErrTimeout
 }
 
-// ReadDataRaw will get one raw reading from chip.
-// Usually will need to call Reset before calling this and Shutdown after.
-func (hx711 *Hx711) ReadDataRaw() (int, error) {
+// readDataRaw will get one raw reading from chip, then send endPulses clock pulses
+// to select the channel and gain of the next conversion.
+// Also returns the longest time the clock pin was high during the reading.
+func (hx711 *Hx711) readDataRaw(endPulses int) (int, time.Duration, error) {
 	err := hx711.waitForDataReady()
 	if err != nil {
-		return 0, fmt.Errorf("waitForDataReady error: %v", err)
+		return 0, 0, fmt.Errorf("waitForDataReady error: %v", err)
 	}
 
 	var level rpio.State
 	var data int
+	var clockHigh time.Duration
+	var maxClockHigh time.Duration
 	for i := 0; i < 24; i++ {
-		err = hx711.setClockHighThenLow()
+		clockHigh, err = hx711.setClockHighThenLow()
 		if err != nil {
-			return 0, fmt.Errorf("setClockHighThenLow error: %v", err)
+			return 0, 0, fmt.Errorf("setClockHighThenLow error: %v", err)
+		}
+		if clockHigh > maxClockHigh {
+			maxClockHigh = clockHigh
 		}
 
 		level = hx711.dataPin.Read()
@@ -113,10 +177,13 @@ func (hx711 *Hx711) ReadDataRaw() (int, error) {
 		}
 	}
 
-	for i := 0; i < hx711.numEndPulses; i++ {
-		err = hx711.setClockHighThenLow()
+	for i := 0; i < endPulses; i++ {
+		clockHigh, err = hx711.setClockHighThenLow()
 		if err != nil {
-			return 0, fmt.Errorf("setClockHighThenLow error: %v", err)
+			return 0, 0, fmt.Errorf("setClockHighThenLow error: %v", err)
+		}
+		if clockHigh > maxClockHigh {
+			maxClockHigh = clockHigh
 		}
 	}
 
@@ -128,5 +195,73 @@ func (hx711 *Hx711) ReadDataRaw() (int, error) {
 		data |= ^0xffffff
 	}
 
-	return data, nil
+	return data, maxClockHigh, nil
+}
+
+// gpioOutput is an Output using a GPIO pin
+type gpioOutput struct {
+	pin rpio.Pin
+}
+
+// NewGPIOOutput creates an Output, such as for FillController, that sets the pin high for on.
+// The pin number must comply with BCM numbering schema.
+func NewGPIOOutput(pinName string) (Output, error) {
+	pin, err := strconv.ParseInt(pinName, 10, 32)
+	if err != nil {
+		return nil, err
+	}
+	output := &gpioOutput{pin: rpio.Pin(int(pin))}
+	output.pin.Output()
+	output.pin.Write(rpio.Low)
+	return output, nil
+}
+
+// Set sets the pin high for on and low for off
+func (output *gpioOutput) Set(on bool) error {
+	if on {
+		output.pin.Write(rpio.High)
+	} else {
+		output.pin.Write(rpio.Low)
+	}
+	return nil
+}
+
+// NewGPIOPulseCounter creates a PulseCounter, such as for BeltScale, that counts rising edges on the pin.
+// The pin number must comply with BCM numbering schema.
+// The edge is polled every millisecond, so pulses need to be slower than 500 per second.
+// Call Close to stop counting.
+func NewGPIOPulseCounter(pinName string, distancePerPulse float64) (*PulseCounter, error) {
+	pinNumber, err := strconv.ParseInt(pinName, 10, 32)
+	if err != nil {
+		return nil, err
+	}
+	pin := rpio.Pin(int(pinNumber))
+	pin.Input()
+	pin.PullUp()
+	pin.Detect(rpio.RiseEdge)
+
+	pulseCounter := &PulseCounter{
+		DistancePerPulse: distancePerPulse,
+		stop:             make(chan struct{}),
+		stopped:          make(chan struct{}),
+	}
+
+	go func() {
+		defer close(pulseCounter.stopped)
+		ticker := time.NewTicker(time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-pulseCounter.stop:
+				pin.Detect(rpio.NoEdge)
+				return
+			case <-ticker.C:
+				if pin.EdgeDetected() {
+					pulseCounter.Pulse(time.Now())
+				}
+			}
+		}
+	}()
+
+	return pulseCounter, nil
 }
@@ -4,6 +4,8 @@ package hx711
 
 import (
 	"time"
+
+	"periph.io/x/periph/conn/physic"
 )
 
 // HostInit calls periph.io host.Init(). This needs to be done before Hx711 can be used.
@@ -18,20 +20,46 @@ func NewHx711(clockPinName string, dataPinName string) (*Hx711, error) {
 	return &Hx711{}, nil
 }
 
-// SetGain can be set to gain of 128, 64, or 32.
-// Gain of 128 or 64 is input channel A, gain of 32 is input channel B.
-// Default gain is 128.
-// Note change only takes affect after one reading.
-func (hx711 *Hx711) SetGain(gain int) {
+// NewHx711WithConfig creates new Hx711 using config.
+// Make sure to set the pin names to the correct pins.
+// https://cdn.sparkfun.com/datasheets/Sensors/ForceFlex/hx711_english.pdf
+func NewHx711WithConfig(config Config) (*Hx711, error) {
+	return &Hx711{}, nil
 }
 
-// setClockHighThenLow sets clock pin high then low
-func (hx711 *Hx711) setClockHighThenLow() error {
+// SetRate sets the RATE pin for an output data rate of 10 or 80 samples per second.
+// Needs RatePinName set in the Config.
+// After a rate change the output takes 4 conversion periods to settle.
+func (hx711 *Hx711) SetRate(samplesPerSecond int) error {
 	return nil
 }
 
+// MeasureRate measures the output data rate of the chip in samples per second over numReadings readings.
+// Useful to confirm how the RATE pin is wired.
+// Do not call Reset before or Shutdown after.
+// Reset and Shutdown are called for you.
+func (hx711 *Hx711) MeasureRate(numReadings int) (float64, error) {
+	return 0, nil
+}
+
+// VerifyRate measures the output data rate of the chip over numReadings readings
+// and returns an error if it does not match SamplesPerSecond.
+// Do not call Reset before or Shutdown after.
+// Reset and Shutdown are called for you.
+func (hx711 *Hx711) VerifyRate(numReadings int) error {
+	return nil
+}
+
+// setClockHighThenLow sets clock pin high then low.
+// Returns about how long the clock pin was high.
+func (hx711 *Hx711) setClockHighThenLow() (time.Duration, error) {
+	return 0, nil
+}
+
 // Reset starts up or resets the chip.
 // The chip needs to be reset if it is not used for just about any amount of time.
+// Readings are thrown away until the chip has settled after the reset.
+// Stops a pending IdleTimeout shutdown, call Shutdown when done.
 func (hx711 *Hx711) Reset() error {
 	return nil
 }
@@ -42,6 +70,22 @@ func (hx711 *Hx711) Shutdown() error {
 	return nil
 }
 
+// Acquire powers up the chip, if needed, and keeps it powered up until Release is called.
+// Reads done between Acquire and Release share one powered up period,
+// so there is no power up settling time between them.
+// Calls can be nested, the chip is shutdown after the last Release,
+// or IdleTimeout after the last Release if IdleTimeout is set.
+func (hx711 *Hx711) Acquire() error {
+	return nil
+}
+
+// Release ends a powered up period started with Acquire.
+// After the last Release the chip is shutdown, or if IdleTimeout is set, shutdown after IdleTimeout
+// unless Acquire is called again before then.
+func (hx711 *Hx711) Release() error {
+	return nil
+}
+
 // waitForDataReady waits for data to go to low which means chip is ready
 func (hx711 *Hx711) waitForDataReady() error {
 	return nil
@@ -49,10 +93,30 @@ func (hx711 *Hx711) waitForDataReady() error {
 
 // ReadDataRaw will get one raw reading from chip.
 // Usually will need to call Reset before calling this and Shutdown after.
+// Readings where the clock pin was held high for longer than MaxClockHigh are corrupt,
+// those are counted and retried up to ReadRetries times.
+// Readings taken before the chip has settled after Reset, power down, rate change,
+// or gain change are thrown away.
+// Stops a pending IdleTimeout shutdown so it can not power down the chip during the reading.
 func (hx711 *Hx711) ReadDataRaw() (int, error) {
 	return 0, nil
 }
 
+// ReadDataInterleavedRaw will get one raw reading from channel A and then one raw reading from channel B.
+// Channel A is at gain of 128, or 64 if SetGain(64) was called. Channel B is at gain of 32.
+// The chip switches channel every reading, the readings are not given settling time after each switch,
+// but the next ReadDataRaw throws away readings until the chip has settled at the requested gain.
+// Usually will need to call Reset before calling this and Shutdown after.
+func (hx711 *Hx711) ReadDataInterleavedRaw() (int, int, error) {
+	return 0, 0, nil
+}
+
+// TimingViolations returns the number of readings that had the clock pin held high for too long.
+// These readings are corrupt and have been thrown away.
+func (hx711 *Hx711) TimingViolations() int {
+	return 0
+}
+
 // readDataMedianRaw will get median of numReadings raw readings.
 func (hx711 *Hx711) readDataMedianRaw(numReadings int, stop *bool) (int, error) {
 	return 0, nil
@@ -61,23 +125,37 @@ func (hx711 *Hx711) readDataMedianRaw(numReadings int, stop *bool) (int, error)
 // ReadDataMedianRaw will get median of numReadings raw readings.
 // Do not call Reset before or Shutdown after.
 // Reset and Shutdown are called for you.
+// Call between Acquire and Release to keep the chip powered up across calls.
 func (hx711 *Hx711) ReadDataMedianRaw(numReadings int) (int, error) {
 	return 0, nil
 }
 
 // ReadDataMedian will get median of numReadings raw readings,
-// then will adjust number with AdjustZero and AdjustScale.
+// then will adjust number with AdjustZero and AdjustScale,
+// or the calibration for the gain if calibrations have been set with SetCalibration.
+// If LoadCompensation is set the number is corrected for creep and hysteresis.
 // Do not call Reset before or Shutdown after.
 // Reset and Shutdown are called for you.
 func (hx711 *Hx711) ReadDataMedian(numReadings int) (float64, error) {
 	return 0, nil
 }
 
+// ReadDataInterleavedMedian will get median of numReadings interleaved raw readings of each channel,
+// then will adjust channel A with AdjustZero and AdjustScale and channel B with AdjustZeroB and AdjustScaleB.
+// If LoadCompensation is set channel A is corrected for creep and hysteresis.
+// Returns channel A then channel B.
+// Do not call Reset before or Shutdown after.
+// Reset and Shutdown are called for you.
+func (hx711 *Hx711) ReadDataInterleavedMedian(numReadings int) (float64, float64, error) {
+	return 0, 0, nil
+}
+
 // ReadDataMedianThenAvg will get median of numReadings raw readings,
 // then do that numAvgs number of time, and average those.
 // then will adjust number with AdjustZero and AdjustScale.
 // Do not call Reset before or Shutdown after.
 // Reset and Shutdown are called for you.
+// The chip stays powered up for all the readings.
 func (hx711 *Hx711) ReadDataMedianThenAvg(numReadings, numAvgs int) (float64, error) {
 	return 0, nil
 }
@@ -99,6 +177,7 @@ func (hx711 *Hx711) ReadDataMedianThenMovingAvgs(numReadings, numAvgs int, previ
 // BackgroundReadMovingAvgs it means to run in the background, run as a Goroutine.
 // Will continue to get readings and update movingAvg until stop is set to true.
 // After it has been stopped, the stopped chan will be closed.
+// Watchers added with AddWatcher are updated with each new movingAvg.
 // Do not call Reset before or Shutdown after.
 // Reset and Shutdown are called for you.
 func (hx711 *Hx711) BackgroundReadMovingAvgs(numReadings, numAvgs int, movingAvg *float64, stop *bool, stopped chan struct{}) {
@@ -109,8 +188,104 @@ func (hx711 *Hx711) BackgroundReadMovingAvgs(numReadings, numAvgs int, movingAvg
 	close(stopped)
 }
 
+// BackgroundReadings it meant to be run in the background, run as a Goroutine.
+// Will continue to get median of numReadings raw readings, adjusted like ReadDataMedian,
+// and send them with the time to readings until stop is set to true.
+// Use numReadings of 1 to get every reading at the full rate of the chip.
+// Readings are dropped if readings chan is full, so use a buffered chan.
+// After it has been stopped, the stopped chan will be closed.
+// Do not call Reset before or Shutdown after.
+// Reset and Shutdown are called for you.
+func (hx711 *Hx711) BackgroundReadings(numReadings int, readings chan<- Reading, stop *bool, stopped chan struct{}) {
+	for !*stop {
+		time.Sleep(200 * time.Millisecond)
+	}
+	close(stopped)
+}
+
+// Weigh will get median of numReadings raw readings, adjusted like ReadDataMedian, until stabilizer says they are stable
+// or timeout has passed, then returns a Weighing of the stable reading with Gross, Tare, and Net in DisplayUnit.
+// If the readings did not settle before timeout, the Weighing is of the last reading with Stable false.
+// Do not call Reset before or Shutdown after.
+// Reset and Shutdown are called for you.
+func (hx711 *Hx711) Weigh(numReadings int, stabilizer Stabilizer, timeout time.Duration) (Weighing, error) {
+	return Weighing{}, nil
+}
+
+// RunPeakHold it meant to be run in the background, run as a Goroutine.
+// Keeps the chip powered up and updates peakHold with every reading at the full rate of the chip,
+// adjusted with the calibration but without median filtering or LoadCompensation,
+// until stop is set to true.
+// After it has been stopped, the stopped chan will be closed.
+// Do not call Reset before or Shutdown after.
+// Reset and Shutdown are called for you.
+func (hx711 *Hx711) RunPeakHold(peakHold *PeakHold, stop *bool, stopped chan struct{}) {
+	for !*stop {
+		time.Sleep(200 * time.Millisecond)
+	}
+	close(stopped)
+}
+
+// RunCapture arms capture then keeps the chip powered up and passes it every reading
+// at the full rate of the chip, adjusted with the calibration but without median filtering or LoadCompensation,
+// until the capture is done or stop is set to true.
+// Do not call Reset before or Shutdown after.
+// Reset and Shutdown are called for you.
+// Returns an error if PreTrigger or PostTrigger is less than 0.
+func (hx711 *Hx711) RunCapture(capture *Capture, stop *bool) (CaptureResult, error) {
+	err := capture.validate()
+	if err != nil {
+		return CaptureResult{}, err
+	}
+
+	capture.Arm()
+	for !*stop {
+		time.Sleep(200 * time.Millisecond)
+	}
+	return CaptureResult{}, ErrCaptureStopped
+}
+
 // GetAdjustValues will help get you the adjust values to plug in later.
 // Do not call Reset before or Shutdown after.
 // Reset and Shutdown are called for you.
 func (hx711 *Hx711) GetAdjustValues(weight1 float64, weight2 float64) {
 }
+
+// String returns the name of the device and its pins
+func (hx711 *Hx711) String() string {
+	return "hx711"
+}
+
+// Halt stops the idle timeout and puts the chip in powered down mode.
+// A read after Halt powers up the chip again.
+func (hx711 *Hx711) Halt() error {
+	return nil
+}
+
+// ReadMass will get median of numReadings raw readings, adjust it like ReadDataMedian,
+// then convert it from Unit to a physic.Mass.
+// Unit needs to be set.
+// Do not call Reset before or Shutdown after.
+// Reset and Shutdown are called for you.
+func (hx711 *Hx711) ReadMass(numReadings int) (physic.Mass, error) {
+	return 0, nil
+}
+
+// ReadForce will get median of numReadings raw readings, adjust it like ReadDataMedian,
+// then convert it from Unit to a physic.Force. Mass units are converted using standard gravity.
+// Unit needs to be set.
+// Do not call Reset before or Shutdown after.
+// Reset and Shutdown are called for you.
+func (hx711 *Hx711) ReadForce(numReadings int) (physic.Force, error) {
+	return 0, nil
+}
+
+// NewGPIOOutput creates an Output, such as for FillController, that sets the pin high for on
+func NewGPIOOutput(pinName string) (Output, error) {
+	return OutputFunc(func(on bool) error { return nil }), nil
+}
+
+// NewGPIOPulseCounter creates a PulseCounter, such as for BeltScale, that counts rising edges on the pin
+func NewGPIOPulseCounter(pinName string, distancePerPulse float64) (*PulseCounter, error) {
+	return &PulseCounter{DistancePerPulse: distancePerPulse}, nil
+}
@@ -0,0 +1,108 @@
+package hx711
+
+import (
+	"math"
+	"time"
+)
+
+// LoadEventType is the type of a LoadEvent
+type LoadEventType int
+
+const (
+	// LoadAdded is when weight was put on the scale
+	LoadAdded LoadEventType = iota
+	// LoadRemoved is when weight was taken off the scale
+	LoadRemoved
+)
+
+// LoadEvent is sent when the settled weight on the scale changes
+type LoadEvent struct {
+	Type LoadEventType
+	// Before is the settled weight before the change
+	Before float64
+	// After is the settled weight after the change
+	After float64
+	// Delta is After minus Before
+	Delta float64
+	Time  time.Time
+}
+
+// LoadEventDetector detects when weight is put on or taken off the scale.
+// Only stable readings are compared, so bumps and other transients are ignored.
+// Pass it readings, such as from BackgroundReadings, with Update.
+type LoadEventDetector struct {
+	// Stabilizer decides when the readings have settled
+	Stabilizer Stabilizer
+	// MinDelta is the smallest change in settled weight that is an event.
+	// Smaller changes are treated as drift and followed without an event.
+	MinDelta float64
+	// Callback if set is called for each event, from the goroutine calling Update
+	Callback func(LoadEvent)
+	// Events if set is sent each event, events are dropped if the channel is full
+	Events chan LoadEvent
+
+	settled    float64
+	hasSettled bool
+}
+
+// String returns the name of the load event type
+func (loadEventType LoadEventType) String() string {
+	switch loadEventType {
+	case LoadAdded:
+		return "added"
+	case LoadRemoved:
+		return "removed"
+	}
+	return "unknown"
+}
+
+// Settled returns the last settled weight and true if there has been one
+func (loadEventDetector *LoadEventDetector) Settled() (float64, bool) {
+	return loadEventDetector.settled, loadEventDetector.hasSettled
+}
+
+// Reset clears the settled weight, the next settled weight is used as the starting weight without an event
+func (loadEventDetector *LoadEventDetector) Reset() {
+	loadEventDetector.Stabilizer.Reset()
+	loadEventDetector.settled = 0
+	loadEventDetector.hasSettled = false
+}
+
+// Update adds a reading. Readings need to be passed in time order.
+// Returns the event and true if the settled weight changed by at least MinDelta.
+func (loadEventDetector *LoadEventDetector) Update(reading Reading) (LoadEvent, bool) {
+	value, stable := loadEventDetector.Stabilizer.Update(reading)
+	if !stable {
+		return LoadEvent{}, false
+	}
+
+	if !loadEventDetector.hasSettled {
+		loadEventDetector.settled = value
+		loadEventDetector.hasSettled = true
+		return LoadEvent{}, false
+	}
+
+	delta := value - loadEventDetector.settled
+	if math.Abs(delta) < loadEventDetector.MinDelta {
+		loadEventDetector.settled = value
+		return LoadEvent{}, false
+	}
+
+	event := LoadEvent{Type: LoadAdded, Before: loadEventDetector.settled, After: value, Delta: delta, Time: reading.Time}
+	if delta < 0 {
+		event.Type = LoadRemoved
+	}
+	loadEventDetector.settled = value
+
+	if loadEventDetector.Callback != nil {
+		loadEventDetector.Callback(event)
+	}
+	if loadEventDetector.Events != nil {
+		select {
+		case loadEventDetector.Events <- event:
+		default:
+		}
+	}
+
+	return event, true
+}
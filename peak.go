@@ -0,0 +1,105 @@
+package hx711
+
+import (
+	"sync"
+	"time"
+)
+
+// ForceSign is the sign convention for bipolar force readings
+type ForceSign int
+
+const (
+	// TensionPositive keeps the sign of calibrated readings, calibrate with tension as positive
+	TensionPositive ForceSign = iota
+	// CompressionPositive flips the sign of calibrated readings so compression is positive
+	CompressionPositive
+)
+
+// Peak is a peak value and the time it was read
+type Peak struct {
+	Value float64
+	Time  time.Time
+}
+
+// PeakHold holds the maximum and minimum readings since the last Reset.
+// It is safe to read and Reset from other goroutines while RunPeakHold is updating it.
+type PeakHold struct {
+	// Sign is the sign convention of the held values
+	Sign ForceSign
+
+	mutex   sync.Mutex
+	max     Peak
+	min     Peak
+	last    Peak
+	samples int
+}
+
+// String returns the name of the sign convention
+func (forceSign ForceSign) String() string {
+	switch forceSign {
+	case TensionPositive:
+		return "tension positive"
+	case CompressionPositive:
+		return "compression positive"
+	}
+	return "unknown"
+}
+
+// Update adds a calibrated reading, applying the sign convention
+func (peakHold *PeakHold) Update(reading Reading) {
+	value := reading.Value
+	if peakHold.Sign == CompressionPositive {
+		value = -value
+	}
+	peak := Peak{Value: value, Time: reading.Time}
+
+	peakHold.mutex.Lock()
+	defer peakHold.mutex.Unlock()
+
+	if peakHold.samples == 0 || value > peakHold.max.Value {
+		peakHold.max = peak
+	}
+	if peakHold.samples == 0 || value < peakHold.min.Value {
+		peakHold.min = peak
+	}
+	peakHold.last = peak
+	peakHold.samples++
+}
+
+// Max returns the maximum value and false if there have been no readings since Reset
+func (peakHold *PeakHold) Max() (Peak, bool) {
+	peakHold.mutex.Lock()
+	defer peakHold.mutex.Unlock()
+	return peakHold.max, peakHold.samples > 0
+}
+
+// Min returns the minimum value and false if there have been no readings since Reset
+func (peakHold *PeakHold) Min() (Peak, bool) {
+	peakHold.mutex.Lock()
+	defer peakHold.mutex.Unlock()
+	return peakHold.min, peakHold.samples > 0
+}
+
+// Last returns the last value and false if there have been no readings since Reset
+func (peakHold *PeakHold) Last() (Peak, bool) {
+	peakHold.mutex.Lock()
+	defer peakHold.mutex.Unlock()
+	return peakHold.last, peakHold.samples > 0
+}
+
+// Samples returns the number of readings since Reset
+func (peakHold *PeakHold) Samples() int {
+	peakHold.mutex.Lock()
+	defer peakHold.mutex.Unlock()
+	return peakHold.samples
+}
+
+// Reset clears the held values
+func (peakHold *PeakHold) Reset() {
+	peakHold.mutex.Lock()
+	defer peakHold.mutex.Unlock()
+	peakHold.max = Peak{}
+	peakHold.min = Peak{}
+	peakHold.last = Peak{}
+	peakHold.samples = 0
+}
@@ -0,0 +1,57 @@
+// +build !windows,!gpiomem
+
+package hx711
+
+import (
+	"fmt"
+
+	"periph.io/x/periph/conn"
+	"periph.io/x/periph/conn/physic"
+)
+
+var _ conn.Resource = &Hx711{}
+
+// String returns the name of the device and its pins
+func (hx711 *Hx711) String() string {
+	return fmt.Sprintf("hx711{clock: %v, data: %v}", hx711.clockPin, hx711.dataPin)
+}
+
+// Halt stops the idle timeout and puts the chip in powered down mode.
+// A read after Halt powers up the chip again.
+func (hx711 *Hx711) Halt() error {
+	return hx711.Shutdown()
+}
+
+// ReadMass will get median of numReadings raw readings, adjust it like ReadDataMedian,
+// then convert it from Unit to a physic.Mass.
+// Unit needs to be set.
+// Do not call Reset before or Shutdown after.
+// Reset and Shutdown are called for you.
+func (hx711 *Hx711) ReadMass(numReadings int) (physic.Mass, error) {
+	value, err := hx711.ReadDataMedian(numReadings)
+	if err != nil {
+		return 0, err
+	}
+	grams, err := ConvertUnit(value, hx711.Unit, Gram)
+	if err != nil {
+		return 0, err
+	}
+	return physic.Mass(grams * float64(physic.Gram)), nil
+}
+
+// ReadForce will get median of numReadings raw readings, adjust it like ReadDataMedian,
+// then convert it from Unit to a physic.Force. Mass units are converted using standard gravity.
+// Unit needs to be set.
+// Do not call Reset before or Shutdown after.
+// Reset and Shutdown are called for you.
+func (hx711 *Hx711) ReadForce(numReadings int) (physic.Force, error) {
+	value, err := hx711.ReadDataMedian(numReadings)
+	if err != nil {
+		return 0, err
+	}
+	newtons, err := ConvertUnit(value, hx711.Unit, Newton)
+	if err != nil {
+		return 0, err
+	}
+	return physic.Force(newtons * float64(physic.Newton)), nil
+}
@@ -0,0 +1,288 @@
+package hx711
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Product is the preset parameters for weighing one product
+type Product struct {
+	Name string `json:"name"`
+	// Tare is the container weight in DisplayUnit
+	Tare float64 `json:"tare"`
+	// Target is the target net weight in DisplayUnit for checkweighing, 0 if not checkweighed
+	Target float64 `json:"target,omitempty"`
+	// UnderTolerance is how far below Target in DisplayUnit is still accepted
+	UnderTolerance float64 `json:"underTolerance,omitempty"`
+	// OverTolerance is how far above Target in DisplayUnit is still accepted
+	OverTolerance float64 `json:"overTolerance,omitempty"`
+	// PieceWeight is the weight of one piece in DisplayUnit for counting, 0 if not counted
+	PieceWeight float64 `json:"pieceWeight,omitempty"`
+	// ReferencePieces is how many pieces PieceWeight was measured from, used for the count uncertainty, default is 1
+	ReferencePieces int `json:"referencePieces,omitempty"`
+}
+
+// ProductCatalog is a list of products
+type ProductCatalog []Product
+
+// Validate returns an error if the product has a negative or missing parameter
+func (product Product) Validate() error {
+	if product.Name == "" {
+		return fmt.Errorf("Name is empty")
+	}
+	if product.Tare < 0 {
+		return fmt.Errorf("Tare is less than 0")
+	}
+	if product.Target < 0 {
+		return fmt.Errorf("Target is less than 0")
+	}
+	if product.UnderTolerance < 0 || product.OverTolerance < 0 {
+		return fmt.Errorf("tolerance is less than 0")
+	}
+	if product.PieceWeight < 0 {
+		return fmt.Errorf("PieceWeight is less than 0")
+	}
+	if product.ReferencePieces < 0 {
+		return fmt.Errorf("ReferencePieces is less than 0")
+	}
+	return nil
+}
+
+// ReadProductCatalog reads a catalog from a JSON array of products
+func ReadProductCatalog(reader io.Reader) (ProductCatalog, error) {
+	var productCatalog ProductCatalog
+	err := json.NewDecoder(reader).Decode(&productCatalog)
+	if err != nil {
+		return nil, fmt.Errorf("Decode error: %v", err)
+	}
+
+	names := make(map[string]bool, len(productCatalog))
+	for i := range productCatalog {
+		err = productCatalog[i].Validate()
+		if err != nil {
+			return nil, fmt.Errorf("product %v error: %v", i, err)
+		}
+		if names[productCatalog[i].Name] {
+			return nil, fmt.Errorf("product %q is in the catalog more than once", productCatalog[i].Name)
+		}
+		names[productCatalog[i].Name] = true
+	}
+
+	return productCatalog, nil
+}
+
+// LoadProductCatalog reads a catalog from a file with a JSON array of products
+func LoadProductCatalog(path string) (ProductCatalog, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("Open error: %v", err)
+	}
+	defer file.Close()
+
+	return ReadProductCatalog(file)
+}
+
+// Find returns the product with name and true if there is one
+func (productCatalog ProductCatalog) Find(name string) (Product, bool) {
+	for i := range productCatalog {
+		if productCatalog[i].Name == name {
+			return productCatalog[i], true
+		}
+	}
+	return Product{}, false
+}
+
+// SetProduct makes product the active product and sets Tare to its tare.
+// Weighing records get the product name, and checkweighers and piece counters set up with
+// SetupCheckweigher and SetupPieceCounter are changed to its parameters.
+// Returns an error, and keeps the current product, if product is missing a Target or PieceWeight
+// needed by a checkweigher or piece counter that is set up.
+// Not safe to call while another goroutine is calling Update of those checkweighers or piece counters.
+func (hx711 *Hx711) SetProduct(product Product) error {
+	err := product.Validate()
+	if err != nil {
+		return err
+	}
+	if len(hx711.checkweighers) > 0 && product.Target == 0 {
+		return fmt.Errorf("product %q has no Target for the checkweighers", product.Name)
+	}
+	if len(hx711.pieceCounters) > 0 && product.PieceWeight == 0 {
+		return fmt.Errorf("product %q has no PieceWeight for the piece counters", product.Name)
+	}
+	_, err = hx711.displayToUnit(product.Tare)
+	if err != nil {
+		return err
+	}
+
+	hx711.product = &product
+	hx711.Tare = product.Tare
+
+	for _, checkweigher := range hx711.checkweighers {
+		err = hx711.applyCheckweigher(checkweigher)
+		if err != nil {
+			return err
+		}
+		// statistics of the old product do not mean anything against the new target
+		checkweigher.ResetStats()
+	}
+	for _, pieceCounter := range hx711.pieceCounters {
+		err = hx711.applyPieceCounter(pieceCounter)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ClearProduct clears the active product and sets Tare to 0.
+// Checkweighers and piece counters set up with SetupCheckweigher and SetupPieceCounter are removed,
+// and keep the parameters of the last product.
+func (hx711 *Hx711) ClearProduct() {
+	hx711.product = nil
+	hx711.Tare = 0
+	hx711.checkweighers = nil
+	hx711.pieceCounters = nil
+}
+
+// Product returns the active product and true if there is one
+func (hx711 *Hx711) Product() (Product, bool) {
+	if hx711.product == nil {
+		return Product{}, false
+	}
+	return *hx711.product, true
+}
+
+// displayToUnit converts a value in DisplayUnit to Unit, the unit of readings from BackgroundReadings
+func (hx711 *Hx711) displayToUnit(value float64) (float64, error) {
+	if hx711.DisplayUnit == UnitNone {
+		return value, nil
+	}
+	return ConvertUnit(value, hx711.DisplayUnit, hx711.Unit)
+}
+
+// SetupCheckweigher sets the target, tolerances, and tare of checkweigher from the active product and Tare,
+// converted to Unit to match readings from BackgroundReadings.
+// checkweigher is changed again by each SetProduct and SetTare until RemoveCheckweigher or ClearProduct is called.
+func (hx711 *Hx711) SetupCheckweigher(checkweigher *Checkweigher) error {
+	err := hx711.applyCheckweigher(checkweigher)
+	if err != nil {
+		return err
+	}
+	for i := range hx711.checkweighers {
+		if hx711.checkweighers[i] == checkweigher {
+			return nil
+		}
+	}
+	hx711.checkweighers = append(hx711.checkweighers, checkweigher)
+	return nil
+}
+
+// RemoveCheckweigher stops SetProduct and SetTare from changing checkweigher
+func (hx711 *Hx711) RemoveCheckweigher(checkweigher *Checkweigher) {
+	for i := range hx711.checkweighers {
+		if hx711.checkweighers[i] == checkweigher {
+			hx711.checkweighers = append(hx711.checkweighers[:i], hx711.checkweighers[i+1:]...)
+			return
+		}
+	}
+}
+
+// applyCheckweigher sets the target, tolerances, and tare of checkweigher from the active product and Tare
+func (hx711 *Hx711) applyCheckweigher(checkweigher *Checkweigher) error {
+	if hx711.product == nil {
+		return fmt.Errorf("no active product")
+	}
+	if hx711.product.Target == 0 {
+		return fmt.Errorf("product %q has no Target", hx711.product.Name)
+	}
+
+	values := []float64{hx711.product.Target, hx711.product.UnderTolerance, hx711.product.OverTolerance, hx711.Tare}
+	for i := range values {
+		var err error
+		values[i], err = hx711.displayToUnit(values[i])
+		if err != nil {
+			return err
+		}
+	}
+
+	checkweigher.Target = values[0]
+	checkweigher.UnderTolerance = values[1]
+	checkweigher.OverTolerance = values[2]
+	checkweigher.Tare = values[3]
+	return nil
+}
+
+// SetupPieceCounter sets the piece weight and tare of pieceCounter from the active product and Tare,
+// converted to Unit to match readings from BackgroundReadings.
+// pieceCounter is changed again by each SetProduct and SetTare until RemovePieceCounter or ClearProduct is called.
+// SetProduct replaces a piece weight from SampleReference or AutoRefine with the product PieceWeight.
+func (hx711 *Hx711) SetupPieceCounter(pieceCounter *PieceCounter) error {
+	err := hx711.applyPieceCounter(pieceCounter)
+	if err != nil {
+		return err
+	}
+	for i := range hx711.pieceCounters {
+		if hx711.pieceCounters[i] == pieceCounter {
+			return nil
+		}
+	}
+	hx711.pieceCounters = append(hx711.pieceCounters, pieceCounter)
+	return nil
+}
+
+// RemovePieceCounter stops SetProduct and SetTare from changing pieceCounter
+func (hx711 *Hx711) RemovePieceCounter(pieceCounter *PieceCounter) {
+	for i := range hx711.pieceCounters {
+		if hx711.pieceCounters[i] == pieceCounter {
+			hx711.pieceCounters = append(hx711.pieceCounters[:i], hx711.pieceCounters[i+1:]...)
+			return
+		}
+	}
+}
+
+// applyPieceCounter sets the piece weight and tare of pieceCounter from the active product and Tare
+func (hx711 *Hx711) applyPieceCounter(pieceCounter *PieceCounter) error {
+	if hx711.product == nil {
+		return fmt.Errorf("no active product")
+	}
+	if hx711.product.PieceWeight == 0 {
+		return fmt.Errorf("product %q has no PieceWeight", hx711.product.Name)
+	}
+
+	pieceWeight, err := hx711.displayToUnit(hx711.product.PieceWeight)
+	if err != nil {
+		return err
+	}
+	tare, err := hx711.displayToUnit(hx711.Tare)
+	if err != nil {
+		return err
+	}
+
+	referencePieces := hx711.product.ReferencePieces
+	if referencePieces < 1 {
+		referencePieces = 1
+	}
+	err = pieceCounter.SetReference(pieceWeight*float64(referencePieces), referencePieces)
+	if err != nil {
+		return fmt.Errorf("SetReference error: %v", err)
+	}
+	pieceCounter.Tare = tare
+	return nil
+}
+
+// applyTare sets the tare of the checkweighers and piece counters set up with SetupCheckweigher and SetupPieceCounter
+func (hx711 *Hx711) applyTare() error {
+	tare, err := hx711.displayToUnit(hx711.Tare)
+	if err != nil {
+		return err
+	}
+	for _, checkweigher := range hx711.checkweighers {
+		checkweigher.Tare = tare
+	}
+	for _, pieceCounter := range hx711.pieceCounters {
+		pieceCounter.Tare = tare
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package hx711
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSetProduct(t *testing.T) {
+	jar := Product{Name: "jam 450g", Tare: 0.21, Target: 0.45, UnderTolerance: 0.009, OverTolerance: 0.015}
+	smallJar := Product{Name: "jam 340g", Tare: 0.18, Target: 0.34, UnderTolerance: 0.008, OverTolerance: 0.012}
+	bolts := Product{Name: "M6 bolts", Tare: 0.35, PieceWeight: 0.0061, ReferencePieces: 50}
+	boxedJar := Product{Name: "boxed jam", Tare: 0.5, Target: 0.45, PieceWeight: 0.5}
+
+	hx711 := &Hx711{Unit: Gram, DisplayUnit: Kilogram}
+	checkweigher := &Checkweigher{}
+	pieceCounter := &PieceCounter{}
+
+	if hx711.SetupCheckweigher(checkweigher) == nil {
+		t.Fatal("SetupCheckweigher without product expected error")
+	}
+
+	err := hx711.SetProduct(jar)
+	if err != nil {
+		t.Fatalf("SetProduct error: %v", err)
+	}
+	err = hx711.SetupCheckweigher(checkweigher)
+	if err != nil {
+		t.Fatalf("SetupCheckweigher error: %v", err)
+	}
+	err = hx711.SetupCheckweigher(checkweigher)
+	if err != nil {
+		t.Fatalf("SetupCheckweigher again error: %v", err)
+	}
+	if len(hx711.checkweighers) != 1 {
+		t.Fatalf("checkweighers got %v want 1", len(hx711.checkweighers))
+	}
+	if hx711.SetupPieceCounter(pieceCounter) == nil {
+		t.Fatal("SetupPieceCounter without PieceWeight expected error")
+	}
+
+	checkweigher.addStats(CheckResult{Weight: 450, Class: CheckAccept})
+
+	// switching product changes the attached checkweigher without setting it up again
+	err = hx711.SetProduct(smallJar)
+	if err != nil {
+		t.Fatalf("SetProduct error: %v", err)
+	}
+	wantCheckweigher := []float64{340, 8, 12, 180}
+	gotCheckweigher := []float64{checkweigher.Target, checkweigher.UnderTolerance, checkweigher.OverTolerance, checkweigher.Tare}
+	for i := range wantCheckweigher {
+		if math.Abs(gotCheckweigher[i]-wantCheckweigher[i]) > 1e-9 {
+			t.Fatalf("checkweigher Target, tolerances, Tare got %v want %v", gotCheckweigher, wantCheckweigher)
+		}
+	}
+	if checkweigher.Stats().Count != 0 {
+		t.Error("SetProduct did not reset checkweigher stats")
+	}
+
+	// a product the checkweigher can not use is refused and the current product is kept
+	err = hx711.SetProduct(bolts)
+	if err == nil {
+		t.Fatal("SetProduct without Target expected error")
+	}
+	product, ok := hx711.Product()
+	if !ok || product.Name != smallJar.Name || hx711.Tare != smallJar.Tare {
+		t.Fatalf("product got %v %v Tare %v want %v", product.Name, ok, hx711.Tare, smallJar.Name)
+	}
+
+	err = hx711.SetProduct(boxedJar)
+	if err != nil {
+		t.Fatalf("SetProduct error: %v", err)
+	}
+	err = hx711.SetupPieceCounter(pieceCounter)
+	if err != nil {
+		t.Fatalf("SetupPieceCounter error: %v", err)
+	}
+	hx711.RemoveCheckweigher(checkweigher)
+	if len(hx711.checkweighers) != 0 {
+		t.Fatalf("checkweighers got %v want 0", len(hx711.checkweighers))
+	}
+
+	// removed checkweigher keeps the last product, attached piece counter gets the new one
+	err = hx711.SetProduct(bolts)
+	if err != nil {
+		t.Fatalf("SetProduct error: %v", err)
+	}
+	if math.Abs(checkweigher.Target-450) > 1e-9 || math.Abs(checkweigher.Tare-500) > 1e-9 {
+		t.Errorf("removed checkweigher Target %v Tare %v want 450 500", checkweigher.Target, checkweigher.Tare)
+	}
+	pieceWeight, referencePieces := pieceCounter.PieceWeight()
+	if math.Abs(pieceWeight-6.1) > 1e-9 || referencePieces != 50 || math.Abs(pieceCounter.Tare-350) > 1e-9 {
+		t.Errorf("piece counter got %v %v Tare %v want 6.1 50 350", pieceWeight, referencePieces, pieceCounter.Tare)
+	}
+
+	hx711.ClearProduct()
+	if _, ok = hx711.Product(); ok || hx711.Tare != 0 || len(hx711.pieceCounters) != 0 {
+		t.Error("ClearProduct did not clear the product")
+	}
+}
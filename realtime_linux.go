@@ -0,0 +1,41 @@
+// +build linux
+
+package hx711
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// schedFIFO is the Linux SCHED_FIFO scheduling policy
+const schedFIFO = 1
+
+// schedParam is the Linux struct sched_param
+type schedParam struct {
+	priority int32
+}
+
+// setRealTimePriority sets the calling thread to SCHED_FIFO with priority.
+// The thread should be locked with runtime.LockOSThread before calling.
+// Returns a func that puts back the old scheduling policy and priority.
+func setRealTimePriority(priority int) (func(), error) {
+	oldPolicy, _, errno := syscall.RawSyscall(syscall.SYS_SCHED_GETSCHEDULER, 0, 0, 0)
+	if errno != 0 {
+		return nil, errno
+	}
+	var oldParam schedParam
+	_, _, errno = syscall.RawSyscall(syscall.SYS_SCHED_GETPARAM, 0, uintptr(unsafe.Pointer(&oldParam)), 0)
+	if errno != 0 {
+		return nil, errno
+	}
+
+	param := schedParam{priority: int32(priority)}
+	_, _, errno = syscall.RawSyscall(syscall.SYS_SCHED_SETSCHEDULER, 0, schedFIFO, uintptr(unsafe.Pointer(&param)))
+	if errno != 0 {
+		return nil, errno
+	}
+
+	return func() {
+		syscall.RawSyscall(syscall.SYS_SCHED_SETSCHEDULER, 0, oldPolicy, uintptr(unsafe.Pointer(&oldParam)))
+	}, nil
+}
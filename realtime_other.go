@@ -0,0 +1,13 @@
+// +build !linux,!windows
+
+package hx711
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// setRealTimePriority is only supported on Linux
+func setRealTimePriority(priority int) (func(), error) {
+	return nil, fmt.Errorf("real-time priority not supported on %v", runtime.GOOS)
+}
@@ -0,0 +1,66 @@
+package hx711
+
+import (
+	"time"
+)
+
+// Reading is a calibrated reading and the time it was read
+type Reading struct {
+	Time  time.Time
+	Value float64
+}
+
+// Stabilizer decides when readings are stable.
+// Readings are stable when all the readings over Duration are within Tolerance of each other.
+type Stabilizer struct {
+	// Tolerance is the most the readings can differ by and still be stable
+	Tolerance float64
+	// Duration is how long the readings need to be within Tolerance
+	Duration time.Duration
+
+	window []Reading
+}
+
+// Reset clears the readings, the next readings are not stable until Duration has passed
+func (stabilizer *Stabilizer) Reset() {
+	stabilizer.window = stabilizer.window[:0]
+}
+
+// Update adds a reading. Readings need to be passed in time order.
+// Returns the average of the readings over Duration and true if they are stable.
+func (stabilizer *Stabilizer) Update(reading Reading) (float64, bool) {
+	stabilizer.window = append(stabilizer.window, reading)
+
+	// keep one reading at or before the start of Duration so the window covers all of Duration
+	start := reading.Time.Add(-stabilizer.Duration)
+	drop := 0
+	for drop+1 < len(stabilizer.window) && !stabilizer.window[drop+1].Time.After(start) {
+		drop++
+	}
+	if drop > 0 {
+		stabilizer.window = append(stabilizer.window[:0], stabilizer.window[drop:]...)
+	}
+
+	if stabilizer.window[0].Time.After(start) {
+		return 0, false
+	}
+
+	low := stabilizer.window[0].Value
+	high := low
+	var sum float64
+	for i := range stabilizer.window {
+		value := stabilizer.window[i].Value
+		if value < low {
+			low = value
+		}
+		if value > high {
+			high = value
+		}
+		sum += value
+	}
+	if high-low > stabilizer.Tolerance {
+		return 0, false
+	}
+
+	return sum / float64(len(stabilizer.window)), true
+}
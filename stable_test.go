@@ -0,0 +1,78 @@
+package hx711
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// testStart is the time of the first reading of test reading streams
+var testStart = time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// readingStream makes readings every interval starting at testStart
+type readingStream struct {
+	interval time.Duration
+	readings []Reading
+}
+
+// hold adds count readings of value
+func (stream *readingStream) hold(value float64, count int) *readingStream {
+	for i := 0; i < count; i++ {
+		stream.add(value)
+	}
+	return stream
+}
+
+// ramp adds count readings going evenly from the last value to value
+func (stream *readingStream) ramp(value float64, count int) *readingStream {
+	var from float64
+	if len(stream.readings) > 0 {
+		from = stream.readings[len(stream.readings)-1].Value
+	}
+	for i := 1; i <= count; i++ {
+		stream.add(from + (value-from)*float64(i)/float64(count))
+	}
+	return stream
+}
+
+// add adds one reading of value
+func (stream *readingStream) add(value float64) {
+	readingTime := testStart.Add(time.Duration(len(stream.readings)) * stream.interval)
+	stream.readings = append(stream.readings, Reading{Time: readingTime, Value: value})
+}
+
+// next returns the time of the next reading
+func (stream *readingStream) next() time.Time {
+	return testStart.Add(time.Duration(len(stream.readings)) * stream.interval)
+}
+
+func TestStabilizer(t *testing.T) {
+	tests := []struct {
+		name       string
+		stream     *readingStream
+		wantStable bool
+		wantValue  float64
+	}{
+		{name: "too short", stream: (&readingStream{interval: 100 * time.Millisecond}).hold(5, 5), wantStable: false},
+		{name: "steady", stream: (&readingStream{interval: 100 * time.Millisecond}).hold(5, 11), wantStable: true, wantValue: 5},
+		{name: "moving", stream: (&readingStream{interval: 100 * time.Millisecond}).ramp(10, 11), wantStable: false},
+		{name: "settled after moving", stream: (&readingStream{interval: 100 * time.Millisecond}).ramp(10, 5).hold(10, 11), wantStable: true, wantValue: 10},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			stabilizer := Stabilizer{Tolerance: 0.5, Duration: time.Second}
+			var value float64
+			var stable bool
+			for _, reading := range test.stream.readings {
+				value, stable = stabilizer.Update(reading)
+			}
+			if stable != test.wantStable {
+				t.Fatalf("stable got %v want %v", stable, test.wantStable)
+			}
+			if stable && math.Abs(value-test.wantValue) > 1e-9 {
+				t.Errorf("value got %v want %v", value, test.wantValue)
+			}
+		})
+	}
+}
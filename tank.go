@@ -0,0 +1,97 @@
+package hx711
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Tank converts the net weight of the liquid in a tank to volume and fill level
+type Tank struct {
+	// Density is the liquid density in kilograms per liter at ReferenceTemperature
+	Density float64
+	// ExpansionCoefficient is the volumetric thermal expansion of the liquid per degree,
+	// such as 0.00021 for water near 20 °C, 0 for a fixed density
+	ExpansionCoefficient float64
+	// ReferenceTemperature is the temperature Density was measured at
+	ReferenceTemperature float64
+	// Temperature is the liquid temperature, needed if ExpansionCoefficient is set
+	Temperature TemperatureSource
+	// Capacity is the tank volume in liters, 0 for no fill percentage
+	Capacity float64
+}
+
+// TankLevel is the contents of a Tank
+type TankLevel struct {
+	// Weight is the net weight of the liquid
+	Weight Weight
+	// Volume is in liters
+	Volume float64
+	// Fill is the percentage of Capacity, 0 if Capacity is not set
+	Fill float64
+	// Density is the density used in kilograms per liter
+	Density float64
+	// Temperature is the liquid temperature used, 0 if ExpansionCoefficient is not set
+	Temperature float64
+}
+
+// DensityAt returns the density in kilograms per liter at temperature
+func (tank *Tank) DensityAt(temperature float64) float64 {
+	return tank.Density / (1 + tank.ExpansionCoefficient*(temperature-tank.ReferenceTemperature))
+}
+
+// Level converts the net weight of the liquid to volume and fill level
+func (tank *Tank) Level(weight Weight) (TankLevel, error) {
+	if tank.Density <= 0 {
+		return TankLevel{}, fmt.Errorf("Density not set")
+	}
+	if weight.Unit == UnitNone {
+		return TankLevel{}, fmt.Errorf("weight has no unit")
+	}
+
+	kilograms, err := ConvertUnit(weight.Value, weight.Unit, Kilogram)
+	if err != nil {
+		return TankLevel{}, err
+	}
+
+	tankLevel := TankLevel{Weight: weight, Density: tank.Density}
+	if tank.ExpansionCoefficient != 0 {
+		if tank.Temperature == nil {
+			return TankLevel{}, fmt.Errorf("Temperature is nil")
+		}
+		tankLevel.Temperature, err = tank.Temperature.Temperature()
+		if err != nil {
+			return TankLevel{}, fmt.Errorf("Temperature error: %v", err)
+		}
+		tankLevel.Density = tank.DensityAt(tankLevel.Temperature)
+	}
+
+	tankLevel.Volume = kilograms / tankLevel.Density
+	if tank.Capacity > 0 {
+		tankLevel.Fill = 100 * tankLevel.Volume / tank.Capacity
+	}
+
+	return tankLevel, nil
+}
+
+// String returns the weight, volume, and fill level, such as 12.35 kg 12.4 L 41.2%
+func (tankLevel TankLevel) String() string {
+	text := tankLevel.Weight.String() + " " + strconv.FormatFloat(tankLevel.Volume, 'f', 1, 64) + " L"
+	if tankLevel.Fill != 0 {
+		text += " " + strconv.FormatFloat(tankLevel.Fill, 'f', 1, 64) + "%"
+	}
+	return text
+}
+
+// ReadTankLevel will get a Weight like ReadWeight, subtract Tare, then convert it to volume and fill level with Tank.
+// Do not call Reset before or Shutdown after.
+// Reset and Shutdown are called for you.
+func (hx711 *Hx711) ReadTankLevel(numReadings int) (TankLevel, error) {
+	if hx711.Tank == nil {
+		return TankLevel{}, fmt.Errorf("Tank is nil")
+	}
+	weight, err := hx711.ReadWeight(numReadings)
+	if err != nil {
+		return TankLevel{}, err
+	}
+	return hx711.Tank.Level(hx711.NetWeight(weight))
+}
@@ -0,0 +1,250 @@
+package hx711
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultTemperatureMaxAge is how long a temperature is used before reading it again
+const defaultTemperatureMaxAge = 10 * time.Second
+
+// TemperatureSource returns the current temperature in degrees Celsius
+type TemperatureSource interface {
+	Temperature() (float64, error)
+}
+
+// TemperatureFunc is a func that can be used as a TemperatureSource
+type TemperatureFunc func() (float64, error)
+
+// Temperature calls the func
+func (temperatureFunc TemperatureFunc) Temperature() (float64, error) {
+	return temperatureFunc()
+}
+
+// FileTemperature reads the temperature from a file that has only a number in it,
+// like /sys/class/thermal/thermal_zone0/temp
+type FileTemperature struct {
+	Path string
+	// Scale is what the number is multiplied by to get degrees Celsius, default is 1.
+	// For /sys/class/thermal files use 0.001
+	Scale float64
+}
+
+// Temperature reads the temperature from the file
+func (fileTemperature *FileTemperature) Temperature() (float64, error) {
+	data, err := ioutil.ReadFile(fileTemperature.Path)
+	if err != nil {
+		return 0, err
+	}
+	temperature, err := strconv.ParseFloat(strings.TrimSpace(string(data)), 64)
+	if err != nil {
+		return 0, fmt.Errorf("ParseFloat error: %v", err)
+	}
+	if fileTemperature.Scale != 0 {
+		temperature *= fileTemperature.Scale
+	}
+	return temperature, nil
+}
+
+// DS18B20 reads the temperature from a DS18B20 sensor using the Linux 1-wire sysfs interface.
+// The w1-gpio and w1-therm kernel modules need to be loaded.
+type DS18B20 struct {
+	// ID is the 1-wire id of the sensor, like 28-0316a2795bff
+	ID string
+	// DevicesPath is the 1-wire devices directory, default is /sys/bus/w1/devices
+	DevicesPath string
+}
+
+// Temperature reads the temperature from the sensor.
+// A reading takes around 750 milliseconds.
+func (ds18b20 *DS18B20) Temperature() (float64, error) {
+	devicesPath := ds18b20.DevicesPath
+	if devicesPath == "" {
+		devicesPath = "/sys/bus/w1/devices"
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(devicesPath, ds18b20.ID, "w1_slave"))
+	if err != nil {
+		return 0, err
+	}
+
+	// first line ends with crc check result, second line ends with t= in thousandths of a degree
+	// 72 01 4b 46 7f ff 0e 10 57 : crc=57 YES
+	// 72 01 4b 46 7f ff 0e 10 57 t=23125
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) < 2 {
+		return 0, fmt.Errorf("w1_slave format not known")
+	}
+	if !strings.HasSuffix(strings.TrimSpace(lines[0]), "YES") {
+		return 0, fmt.Errorf("crc check failed")
+	}
+	index := strings.LastIndex(lines[1], "t=")
+	if index < 0 {
+		return 0, fmt.Errorf("w1_slave format not known")
+	}
+	temperature, err := strconv.ParseFloat(strings.TrimSpace(lines[1][index+2:]), 64)
+	if err != nil {
+		return 0, fmt.Errorf("ParseFloat error: %v", err)
+	}
+
+	return temperature / 1000, nil
+}
+
+// TemperatureCompensation adjusts readings for the temperature of the load cell and chip.
+// Set Hx711 TemperatureCompensation to use it.
+type TemperatureCompensation struct {
+	Source TemperatureSource
+	// ReferenceTemperature is the temperature the calibration was done at
+	ReferenceTemperature float64
+	// ZeroCoefficient is the change of the raw zero reading per degree
+	ZeroCoefficient float64
+	// SpanCoefficient is the relative change of AdjustScale per degree
+	SpanCoefficient float64
+	// Gain is the gain the coefficients were found at, default of 0 is gain of 128.
+	// ZeroCoefficient is in raw counts at that gain and channel, so readings at other gains are not compensated.
+	Gain Gain
+	// MaxAge is how long a temperature is used before reading it again, default is 10 seconds
+	MaxAge time.Duration
+
+	temperature     float64
+	temperatureTime time.Time
+}
+
+// TemperatureSample is a raw reading at a temperature with a known load on the scale
+type TemperatureSample struct {
+	Temperature float64
+	Raw         int
+	// Gain is the gain Raw was read at
+	Gain Gain
+	// Load is the known weight on the scale, 0 when empty
+	Load float64
+}
+
+// Temperature returns the temperature from Source, only reading Source again after MaxAge
+func (temperatureCompensation *TemperatureCompensation) Temperature() (float64, error) {
+	maxAge := temperatureCompensation.MaxAge
+	if maxAge <= 0 {
+		maxAge = defaultTemperatureMaxAge
+	}
+	if !temperatureCompensation.temperatureTime.IsZero() && time.Since(temperatureCompensation.temperatureTime) < maxAge {
+		return temperatureCompensation.temperature, nil
+	}
+
+	if temperatureCompensation.Source == nil {
+		return 0, fmt.Errorf("Source is nil")
+	}
+	temperature, err := temperatureCompensation.Source.Temperature()
+	if err != nil {
+		return 0, err
+	}
+
+	temperatureCompensation.temperature = temperature
+	temperatureCompensation.temperatureTime = time.Now()
+	return temperature, nil
+}
+
+// appliesTo returns true if the coefficients are for readings at gain
+func (temperatureCompensation *TemperatureCompensation) appliesTo(gain Gain) bool {
+	if temperatureCompensation.Gain == 0 {
+		return gain == Gain128
+	}
+	return gain == temperatureCompensation.Gain
+}
+
+// compensate returns adjustZero and adjustScale corrected for the current temperature
+func (temperatureCompensation *TemperatureCompensation) compensate(adjustZero float64, adjustScale float64) (float64, float64, error) {
+	temperature, err := temperatureCompensation.Temperature()
+	if err != nil {
+		return 0, 0, fmt.Errorf("Temperature error: %v", err)
+	}
+	delta := temperature - temperatureCompensation.ReferenceTemperature
+	return adjustZero + temperatureCompensation.ZeroCoefficient*delta,
+		adjustScale * (1 + temperatureCompensation.SpanCoefficient*delta), nil
+}
+
+// Fit sets ZeroCoefficient, SpanCoefficient, and Gain from samples logged over a temperature sweep.
+// All the samples need to be at the same gain.
+// Needs samples with the scale empty, Load of 0, from at least 2 temperatures.
+// SpanCoefficient also needs samples with a known load from at least 2 temperatures,
+// otherwise SpanCoefficient is set to 0.
+func (temperatureCompensation *TemperatureCompensation) Fit(samples []TemperatureSample) error {
+	var gain Gain
+	for i := range samples {
+		sampleGain := samples[i].Gain
+		if sampleGain == 0 {
+			sampleGain = Gain128
+		}
+		if i > 0 && sampleGain != gain {
+			return fmt.Errorf("samples are at gain of %v and %v", int(gain), int(sampleGain))
+		}
+		gain = sampleGain
+	}
+
+	var zeroTemperatures []float64
+	var zeroRaws []float64
+	for i := range samples {
+		if samples[i].Load == 0 {
+			zeroTemperatures = append(zeroTemperatures, samples[i].Temperature-temperatureCompensation.ReferenceTemperature)
+			zeroRaws = append(zeroRaws, float64(samples[i].Raw))
+		}
+	}
+	zero, zeroCoefficient, err := linearFit(zeroTemperatures, zeroRaws)
+	if err != nil {
+		return fmt.Errorf("zero fit error: %v", err)
+	}
+
+	var loadTemperatures []float64
+	var sensitivities []float64
+	for i := range samples {
+		if samples[i].Load != 0 {
+			delta := samples[i].Temperature - temperatureCompensation.ReferenceTemperature
+			loadTemperatures = append(loadTemperatures, delta)
+			sensitivities = append(sensitivities, (float64(samples[i].Raw)-(zero+zeroCoefficient*delta))/samples[i].Load)
+		}
+	}
+
+	var spanCoefficient float64
+	if len(loadTemperatures) > 0 {
+		sensitivity, slope, err := linearFit(loadTemperatures, sensitivities)
+		if err != nil {
+			return fmt.Errorf("span fit error: %v", err)
+		}
+		if sensitivity == 0 {
+			return fmt.Errorf("span fit error: sensitivity is 0")
+		}
+		spanCoefficient = slope / sensitivity
+	}
+
+	temperatureCompensation.ZeroCoefficient = zeroCoefficient
+	temperatureCompensation.SpanCoefficient = spanCoefficient
+	temperatureCompensation.Gain = gain
+	return nil
+}
+
+// ReadTemperatureSample will get median of numReadings raw readings and the temperature from
+// TemperatureCompensation Source, for logging a temperature sweep to use with Fit.
+// load is the known weight on the scale, 0 when empty.
+// Do not call Reset before or Shutdown after.
+// Reset and Shutdown are called for you.
+func (hx711 *Hx711) ReadTemperatureSample(numReadings int, load float64) (TemperatureSample, error) {
+	if hx711.TemperatureCompensation == nil || hx711.TemperatureCompensation.Source == nil {
+		return TemperatureSample{}, fmt.Errorf("TemperatureCompensation Source is nil")
+	}
+
+	data, err := hx711.ReadDataMedianRaw(numReadings)
+	if err != nil {
+		return TemperatureSample{}, err
+	}
+
+	temperature, err := hx711.TemperatureCompensation.Source.Temperature()
+	if err != nil {
+		return TemperatureSample{}, fmt.Errorf("Temperature error: %v", err)
+	}
+
+	_, gain := hx711.RequestedGain()
+	return TemperatureSample{Temperature: temperature, Raw: data, Gain: gain, Load: load}, nil
+}
@@ -0,0 +1,115 @@
+package hx711
+
+import (
+	"math"
+	"testing"
+)
+
+// temperatureSamples returns samples of a load cell with zero and sensitivity at 20 degrees
+// that change by zeroCoefficient and spanCoefficient per degree, empty and with load at each temperature
+func temperatureSamples(gain Gain, zero float64, sensitivity float64, zeroCoefficient float64, spanCoefficient float64, load float64, temperatures []float64) []TemperatureSample {
+	var samples []TemperatureSample
+	for _, temperature := range temperatures {
+		delta := temperature - 20
+		empty := zero + zeroCoefficient*delta
+		samples = append(samples, TemperatureSample{Temperature: temperature, Raw: int(math.Round(empty)), Gain: gain})
+		if load != 0 {
+			loaded := empty + load*sensitivity*(1+spanCoefficient*delta)
+			samples = append(samples, TemperatureSample{Temperature: temperature, Raw: int(math.Round(loaded)), Gain: gain, Load: load})
+		}
+	}
+	return samples
+}
+
+func TestTemperatureCompensationFit(t *testing.T) {
+	tests := []struct {
+		name                string
+		samples             []TemperatureSample
+		wantZeroCoefficient float64
+		wantSpanCoefficient float64
+		wantGain            Gain
+		wantErr             bool
+	}{
+		{
+			name:                "zero and span",
+			samples:             temperatureSamples(Gain128, -8000, 400, 12.5, 0.0002, 1000, []float64{5, 15, 25, 35}),
+			wantZeroCoefficient: 12.5,
+			wantSpanCoefficient: 0.0002,
+			wantGain:            Gain128,
+		},
+		{
+			name:                "zero only",
+			samples:             temperatureSamples(Gain64, -4000, 200, -6, 0, 0, []float64{10, 20, 30}),
+			wantZeroCoefficient: -6,
+			wantSpanCoefficient: 0,
+			wantGain:            Gain64,
+		},
+		{
+			name:                "default gain",
+			samples:             temperatureSamples(0, 1000, 100, 3, -0.0005, 500, []float64{0, 20, 40}),
+			wantZeroCoefficient: 3,
+			wantSpanCoefficient: -0.0005,
+			wantGain:            Gain128,
+		},
+		{
+			name:    "one temperature",
+			samples: temperatureSamples(Gain128, -8000, 400, 12.5, 0.0002, 1000, []float64{20}),
+			wantErr: true,
+		},
+		{
+			name: "mixed gains",
+			samples: append(temperatureSamples(Gain128, -8000, 400, 12.5, 0, 0, []float64{10, 30}),
+				temperatureSamples(Gain32, -2000, 100, 3, 0, 0, []float64{10, 30})...),
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			temperatureCompensation := &TemperatureCompensation{ReferenceTemperature: 20}
+			err := temperatureCompensation.Fit(test.samples)
+			if test.wantErr {
+				if err == nil {
+					t.Fatal("Fit expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Fit error: %v", err)
+			}
+
+			// raw readings are rounded to whole counts
+			if math.Abs(temperatureCompensation.ZeroCoefficient-test.wantZeroCoefficient) > 0.05 {
+				t.Errorf("ZeroCoefficient got %v want %v", temperatureCompensation.ZeroCoefficient, test.wantZeroCoefficient)
+			}
+			if math.Abs(temperatureCompensation.SpanCoefficient-test.wantSpanCoefficient) > 0.000005 {
+				t.Errorf("SpanCoefficient got %v want %v", temperatureCompensation.SpanCoefficient, test.wantSpanCoefficient)
+			}
+			if temperatureCompensation.Gain != test.wantGain {
+				t.Errorf("Gain got %v want %v", temperatureCompensation.Gain, test.wantGain)
+			}
+		})
+	}
+}
+
+func TestTemperatureCompensationCompensate(t *testing.T) {
+	temperature := 30.0
+	temperatureCompensation := &TemperatureCompensation{
+		Source:               TemperatureFunc(func() (float64, error) { return temperature, nil }),
+		ReferenceTemperature: 20,
+		ZeroCoefficient:      12.5,
+		SpanCoefficient:      0.0002,
+	}
+
+	zero, scale, err := temperatureCompensation.compensate(-8000, 400)
+	if err != nil {
+		t.Fatalf("compensate error: %v", err)
+	}
+	if math.Abs(zero-(-7875)) > 1e-9 || math.Abs(scale-400.8) > 1e-9 {
+		t.Errorf("compensate got %v %v want -7875 400.8", zero, scale)
+	}
+
+	if !temperatureCompensation.appliesTo(Gain128) || temperatureCompensation.appliesTo(Gain64) || temperatureCompensation.appliesTo(Gain32) {
+		t.Error("default Gain should only apply to gain of 128")
+	}
+}
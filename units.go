@@ -0,0 +1,228 @@
+package hx711
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// Unit is a unit of weight or force
+type Unit int
+
+const (
+	// UnitNone is for readings without a unit
+	UnitNone Unit = iota
+	// Gram is grams
+	Gram
+	// Kilogram is kilograms
+	Kilogram
+	// Pound is avoirdupois pounds
+	Pound
+	// Ounce is avoirdupois ounces
+	Ounce
+	// Newton is newtons, converted to and from mass using standard gravity
+	Newton
+)
+
+const (
+	// standardGravity is in meters per second squared
+	standardGravity = 9.80665
+	// overloadDivisions is how many divisions above capacity is overload
+	overloadDivisions = 9
+	// underloadDivisions is how many divisions below zero is underload
+	underloadDivisions = 20
+)
+
+// Weight is a calibrated reading with its unit
+type Weight struct {
+	Value float64
+	Unit  Unit
+	// Division is the display resolution Value was rounded to, 0 if not rounded
+	Division float64
+	// Overload is true when Value is more than 9 divisions above the capacity of the scale
+	Overload bool
+	// Underload is true when Value is more than 20 divisions below zero
+	Underload bool
+}
+
+// String returns the unit symbol
+func (unit Unit) String() string {
+	switch unit {
+	case UnitNone:
+		return ""
+	case Gram:
+		return "g"
+	case Kilogram:
+		return "kg"
+	case Pound:
+		return "lb"
+	case Ounce:
+		return "oz"
+	case Newton:
+		return "N"
+	}
+	return "unknown"
+}
+
+// MarshalText returns the unit symbol, such as for JSON
+func (unit Unit) MarshalText() ([]byte, error) {
+	if unit < UnitNone || unit > Newton {
+		return nil, fmt.Errorf("unit %v is not known", int(unit))
+	}
+	return []byte(unit.String()), nil
+}
+
+// UnmarshalText sets the unit from a unit symbol, such as from JSON
+func (unit *Unit) UnmarshalText(text []byte) error {
+	for parsed := UnitNone; parsed <= Newton; parsed++ {
+		if string(text) == parsed.String() {
+			*unit = parsed
+			return nil
+		}
+	}
+	return fmt.Errorf("unit %q is not known", text)
+}
+
+// grams returns the number of grams in one of unit
+func (unit Unit) grams() (float64, error) {
+	switch unit {
+	case Gram:
+		return 1, nil
+	case Kilogram:
+		return 1000, nil
+	case Pound:
+		return 453.59237, nil
+	case Ounce:
+		return 28.349523125, nil
+	case Newton:
+		return 1000 / standardGravity, nil
+	}
+	return 0, fmt.Errorf("unit %v can not be converted", int(unit))
+}
+
+// ConvertUnit converts value from one unit to another
+func ConvertUnit(value float64, from Unit, to Unit) (float64, error) {
+	if from == to {
+		return value, nil
+	}
+	fromGrams, err := from.grams()
+	if err != nil {
+		return 0, err
+	}
+	toGrams, err := to.grams()
+	if err != nil {
+		return 0, err
+	}
+	return value * fromGrams / toGrams, nil
+}
+
+// ValidDivision returns true if division is 1, 2, or 5 times a power of 10, like 0.01, 0.2, or 5
+func ValidDivision(division float64) bool {
+	if division <= 0 || math.IsInf(division, 0) || math.IsNaN(division) {
+		return false
+	}
+	exponent := math.Floor(math.Log10(division))
+	mantissa := division / math.Pow(10, exponent)
+	for _, step := range []float64{1, 2, 5, 10} {
+		if math.Abs(mantissa-step) < 1e-9 {
+			return true
+		}
+	}
+	return false
+}
+
+// RoundToDivision rounds value to the nearest multiple of division, halves round away from zero
+func RoundToDivision(value float64, division float64) float64 {
+	if division <= 0 {
+		return value
+	}
+	// remove floating point noise from the number of divisions first,
+	// so halves like 0.15 / 0.1 = 1.4999999999999998 still round away from zero
+	divisions, _ := strconv.ParseFloat(strconv.FormatFloat(value/division, 'f', 9, 64), 64)
+	rounded := math.Round(divisions) * division
+	// remove floating point noise like 0.30000000000000004
+	decimals := divisionDecimals(division)
+	rounded, _ = strconv.ParseFloat(strconv.FormatFloat(rounded, 'f', decimals, 64), 64)
+	return rounded
+}
+
+// divisionDecimals returns the number of decimal places needed to show division
+func divisionDecimals(division float64) int {
+	decimals := 0
+	for decimals < 10 && math.Abs(division-math.Round(division)) > 1e-9*division {
+		division *= 10
+		decimals++
+	}
+	return decimals
+}
+
+// Convert returns the weight in unit. The value is not rounded in the new unit, so Division is 0.
+func (weight Weight) Convert(unit Unit) (Weight, error) {
+	value, err := ConvertUnit(weight.Value, weight.Unit, unit)
+	if err != nil {
+		return Weight{}, err
+	}
+	return Weight{Value: value, Unit: unit, Overload: weight.Overload, Underload: weight.Underload}, nil
+}
+
+// String returns the weight like an indicator would show it, such as 12.35 kg
+func (weight Weight) String() string {
+	if weight.Overload {
+		return "overload"
+	}
+	if weight.Underload {
+		return "underload"
+	}
+	decimals := -1
+	if weight.Division > 0 {
+		decimals = divisionDecimals(weight.Division)
+	}
+	value := strconv.FormatFloat(weight.Value, 'f', decimals, 64)
+	if weight.Unit == UnitNone {
+		return value
+	}
+	return value + " " + weight.Unit.String()
+}
+
+// ToWeight converts a calibrated value in Unit to a Weight in DisplayUnit,
+// rounded to Division, with Overload and Underload set based on Capacity and Division.
+// Overload and Underload compare the rounded value, like the value an indicator would show.
+func (hx711 *Hx711) ToWeight(value float64) (Weight, error) {
+	displayUnit := hx711.DisplayUnit
+	if displayUnit == UnitNone {
+		displayUnit = hx711.Unit
+	}
+
+	value, err := ConvertUnit(value, hx711.Unit, displayUnit)
+	if err != nil {
+		return Weight{}, err
+	}
+
+	weight := Weight{Value: value, Unit: displayUnit}
+	if hx711.Division != 0 {
+		if !ValidDivision(hx711.Division) {
+			return Weight{}, fmt.Errorf("Division of %v is not 1, 2, or 5 times a power of 10", hx711.Division)
+		}
+		weight.Division = hx711.Division
+		weight.Value = RoundToDivision(value, hx711.Division)
+		// a small part of a division keeps floating point noise from going over the limit
+		weight.Underload = weight.Value < -underloadDivisions*hx711.Division-hx711.Division/1000
+	}
+	if hx711.Capacity > 0 {
+		weight.Overload = weight.Value > hx711.Capacity+overloadDivisions*hx711.Division+hx711.Division/1000
+	}
+
+	return weight, nil
+}
+
+// ReadWeight will get median of numReadings raw readings, adjust it like ReadDataMedian,
+// then convert it to a Weight with ToWeight.
+// Do not call Reset before or Shutdown after.
+// Reset and Shutdown are called for you.
+func (hx711 *Hx711) ReadWeight(numReadings int) (Weight, error) {
+	value, err := hx711.ReadDataMedian(numReadings)
+	if err != nil {
+		return Weight{}, err
+	}
+	return hx711.ToWeight(value)
+}
@@ -0,0 +1,136 @@
+package hx711
+
+import (
+	"math"
+	"testing"
+)
+
+func TestValidDivision(t *testing.T) {
+	tests := []struct {
+		division float64
+		want     bool
+	}{
+		{division: 1, want: true},
+		{division: 0.2, want: true},
+		{division: 0.3, want: false},
+		{division: 0.05, want: true},
+		{division: 1e-3, want: true},
+		{division: 0.01, want: true},
+		{division: 5e-7, want: true},
+		{division: 20, want: true},
+		{division: 1000, want: true},
+		{division: 2.5, want: false},
+		{division: 0, want: false},
+		{division: -0.1, want: false},
+		{division: math.Inf(1), want: false},
+		{division: math.NaN(), want: false},
+	}
+
+	for _, test := range tests {
+		got := ValidDivision(test.division)
+		if got != test.want {
+			t.Errorf("ValidDivision %v got %v want %v", test.division, got, test.want)
+		}
+	}
+}
+
+func TestRoundToDivision(t *testing.T) {
+	tests := []struct {
+		value    float64
+		division float64
+		want     float64
+	}{
+		{value: 12.344, division: 0.01, want: 12.34},
+		{value: 12.345, division: 0.01, want: 12.35},
+		{value: 0.1 + 0.2, division: 0.1, want: 0.3},
+		{value: 2.5, division: 1, want: 3},
+		{value: -2.5, division: 1, want: -3},
+		{value: 0.15, division: 0.1, want: 0.2},
+		{value: -0.15, division: 0.1, want: -0.2},
+		{value: 0.35, division: 0.1, want: 0.4},
+		{value: 1.005, division: 0.01, want: 1.01},
+		{value: 0.3, division: 0.2, want: 0.4},
+		{value: 0.29, division: 0.2, want: 0.2},
+		{value: 7.5, division: 5, want: 10},
+		{value: 0.0025, division: 0.005, want: 0.005},
+		{value: 0.0024, division: 0.005, want: 0},
+		{value: 1234.5678, division: 0, want: 1234.5678},
+	}
+
+	for _, test := range tests {
+		got := RoundToDivision(test.value, test.division)
+		if got != test.want {
+			t.Errorf("RoundToDivision %v %v got %v want %v", test.value, test.division, got, test.want)
+		}
+	}
+}
+
+func TestDivisionDecimals(t *testing.T) {
+	tests := []struct {
+		division float64
+		want     int
+	}{
+		{division: 5, want: 0},
+		{division: 1000, want: 0},
+		{division: 0.2, want: 1},
+		{division: 0.05, want: 2},
+		{division: 1e-3, want: 3},
+		{division: 5e-7, want: 7},
+		{division: 1e-9, want: 9},
+	}
+
+	for _, test := range tests {
+		got := divisionDecimals(test.division)
+		if got != test.want {
+			t.Errorf("divisionDecimals %v got %v want %v", test.division, got, test.want)
+		}
+	}
+}
+
+func TestToWeight(t *testing.T) {
+	tests := []struct {
+		name          string
+		displayUnit   Unit
+		division      float64
+		capacity      float64
+		value         float64
+		want          string
+		wantOverload  bool
+		wantUnderload bool
+		wantErr       bool
+	}{
+		{name: "no division", value: 1.23456, want: "1.23456 kg"},
+		{name: "division", division: 0.01, value: 1.005, want: "1.01 kg"},
+		{name: "display unit", displayUnit: Gram, division: 5, value: 1.2324, want: "1230 g"},
+		{name: "invalid division", division: 0.3, value: 1, wantErr: true},
+		{name: "at capacity", division: 0.01, capacity: 10, value: 10, want: "10.00 kg"},
+		{name: "capacity plus 9 divisions", division: 0.01, capacity: 10, value: 10.09, want: "10.09 kg"},
+		{name: "rounds to capacity plus 9 divisions", division: 0.01, capacity: 10, value: 10.0949, want: "10.09 kg"},
+		{name: "capacity plus 10 divisions", division: 0.01, capacity: 10, value: 10.095, want: "overload", wantOverload: true},
+		{name: "capacity without division", capacity: 10, value: 10.001, want: "overload", wantOverload: true},
+		{name: "20 divisions below zero", division: 0.1, value: -2, want: "-2.0 kg"},
+		{name: "21 divisions below zero", division: 0.1, value: -2.05, want: "underload", wantUnderload: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			hx711 := &Hx711{Unit: Kilogram, DisplayUnit: test.displayUnit, Division: test.division, Capacity: test.capacity}
+			weight, err := hx711.ToWeight(test.value)
+			if test.wantErr {
+				if err == nil {
+					t.Fatal("ToWeight expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ToWeight error: %v", err)
+			}
+			if weight.Overload != test.wantOverload || weight.Underload != test.wantUnderload {
+				t.Errorf("Overload %v Underload %v want %v %v", weight.Overload, weight.Underload, test.wantOverload, test.wantUnderload)
+			}
+			if weight.String() != test.want {
+				t.Errorf("String got %q want %q", weight.String(), test.want)
+			}
+		})
+	}
+}
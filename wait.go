@@ -0,0 +1,95 @@
+package hx711
+
+import (
+	"fmt"
+	"time"
+)
+
+// WaitStrategy is how to wait for the chip to be ready
+type WaitStrategy int
+
+const (
+	// WaitDefault uses the default wait for the GPIO access, which is WaitEdge
+	WaitDefault WaitStrategy = iota
+	// WaitEdge waits for the data pin falling edge
+	WaitEdge
+	// WaitPoll reads the data pin every ReadyPollInterval until it is low
+	WaitPoll
+)
+
+const (
+	// defaultSamplesPerSecond is the output data rate of the chip when RATE pin is low
+	defaultSamplesPerSecond = 10
+	// settlingConversions is the number of conversion periods the output takes to settle
+	// after power up, reset, rate change, or gain change.
+	// Datasheet has 400 milliseconds at 10 samples per second and 50 milliseconds at 80.
+	settlingConversions = 4
+	// defaultReadyConversions is the number of conversion periods waited, after the settling time, before timing out.
+	// Looks like chip often takes 80 to 100 milliseconds to get ready at 10 samples per second
+	// but sometimes it takes around 500 milliseconds.
+	defaultReadyConversions = 7
+	// defaultReadyPollInterval is the default time between data pin checks
+	defaultReadyPollInterval = 250 * time.Microsecond
+)
+
+// String returns the name of the wait strategy
+func (waitStrategy WaitStrategy) String() string {
+	switch waitStrategy {
+	case WaitDefault:
+		return "default"
+	case WaitEdge:
+		return "edge"
+	case WaitPoll:
+		return "poll"
+	}
+	return "unknown"
+}
+
+// checkSamplesPerSecond returns an error if samplesPerSecond is not a rate the chip has
+func checkSamplesPerSecond(samplesPerSecond int) error {
+	if samplesPerSecond != 10 && samplesPerSecond != 80 {
+		return fmt.Errorf("samples per second of %v is not 10 or 80", samplesPerSecond)
+	}
+	return nil
+}
+
+// conversionPeriod returns the time between conversions based on SamplesPerSecond
+func (hx711 *Hx711) conversionPeriod() time.Duration {
+	samplesPerSecond := hx711.SamplesPerSecond
+	if samplesPerSecond < 1 {
+		samplesPerSecond = defaultSamplesPerSecond
+	}
+	return time.Second / time.Duration(samplesPerSecond)
+}
+
+// settlingTime returns the time the output takes to settle based on SamplesPerSecond
+func (hx711 *Hx711) settlingTime() time.Duration {
+	return settlingConversions * hx711.conversionPeriod()
+}
+
+// readyTimeout returns ReadyTimeout, or if not set, a timeout based on SamplesPerSecond
+func (hx711 *Hx711) readyTimeout() time.Duration {
+	if hx711.ReadyTimeout > 0 {
+		return hx711.ReadyTimeout
+	}
+	return hx711.settlingTime() + defaultReadyConversions*hx711.conversionPeriod()
+}
+
+// ReadingsFor returns the number of readings the chip makes in duration based on SamplesPerSecond.
+// Useful for sizing median and moving average windows so they cover the same time at either rate.
+// Returns at least 1.
+func (hx711 *Hx711) ReadingsFor(duration time.Duration) int {
+	readings := int(duration / hx711.conversionPeriod())
+	if readings < 1 {
+		return 1
+	}
+	return readings
+}
+
+// readyPollInterval returns ReadyPollInterval, or if not set, the default poll interval
+func (hx711 *Hx711) readyPollInterval() time.Duration {
+	if hx711.ReadyPollInterval > 0 {
+		return hx711.ReadyPollInterval
+	}
+	return defaultReadyPollInterval
+}
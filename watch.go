@@ -0,0 +1,134 @@
+package hx711
+
+import (
+	"time"
+)
+
+// WatchCondition is the condition a Watcher watches for
+type WatchCondition int
+
+const (
+	// WatchAbove is active when the value is above High
+	WatchAbove WatchCondition = iota
+	// WatchBelow is active when the value is below Low
+	WatchBelow
+	// WatchBetween is active when the value is between Low and High
+	WatchBetween
+)
+
+// WatchEvent is sent when a Watcher becomes active or inactive
+type WatchEvent struct {
+	Watcher *Watcher
+	Active  bool
+	Value   float64
+	Time    time.Time
+}
+
+// Watcher watches readings for a condition with hysteresis and a minimum dwell time.
+// Add it to an Hx711 with AddWatcher to have it updated by BackgroundReadMovingAvgs,
+// or call Update with your own readings.
+type Watcher struct {
+	Name      string
+	Condition WatchCondition
+	Low       float64
+	High      float64
+	// Hysteresis is how far back past the threshold the value has to go for the watcher to become inactive
+	Hysteresis float64
+	// MinDwell is how long the condition has to hold before the watcher becomes active or inactive
+	MinDwell time.Duration
+	// Callback if set is called for each event, from the goroutine calling Update
+	Callback func(WatchEvent)
+	// Events if set is sent each event, events are dropped if the channel is full
+	Events chan WatchEvent
+
+	active       bool
+	pending      bool
+	pendingSince time.Time
+}
+
+// Active returns true if the watcher condition is active
+func (watcher *Watcher) Active() bool {
+	return watcher.active
+}
+
+// conditionMet returns true if value meets the condition, using hysteresis if already active
+func (watcher *Watcher) conditionMet(value float64) bool {
+	hysteresis := 0.0
+	if watcher.active {
+		hysteresis = watcher.Hysteresis
+	}
+	switch watcher.Condition {
+	case WatchAbove:
+		return value > watcher.High-hysteresis
+	case WatchBelow:
+		return value < watcher.Low+hysteresis
+	case WatchBetween:
+		return value >= watcher.Low-hysteresis && value <= watcher.High+hysteresis
+	}
+	return false
+}
+
+// Update updates the watcher with a value read at valueTime.
+// Returns the event and true if the watcher became active or inactive.
+func (watcher *Watcher) Update(value float64, valueTime time.Time) (WatchEvent, bool) {
+	if watcher.conditionMet(value) == watcher.active {
+		watcher.pending = false
+		return WatchEvent{}, false
+	}
+
+	if !watcher.pending {
+		watcher.pending = true
+		watcher.pendingSince = valueTime
+	}
+	if valueTime.Sub(watcher.pendingSince) < watcher.MinDwell {
+		return WatchEvent{}, false
+	}
+
+	watcher.pending = false
+	watcher.active = !watcher.active
+	event := WatchEvent{Watcher: watcher, Active: watcher.active, Value: value, Time: valueTime}
+
+	if watcher.Callback != nil {
+		watcher.Callback(event)
+	}
+	if watcher.Events != nil {
+		select {
+		case watcher.Events <- event:
+		default:
+		}
+	}
+
+	return event, true
+}
+
+// AddWatcher adds a watcher that is updated with each moving average from BackgroundReadMovingAvgs
+func (hx711 *Hx711) AddWatcher(watcher *Watcher) {
+	hx711.watchMutex.Lock()
+	hx711.watchers = append(hx711.watchers, watcher)
+	hx711.watchMutex.Unlock()
+}
+
+// RemoveWatcher removes a watcher added with AddWatcher
+func (hx711 *Hx711) RemoveWatcher(watcher *Watcher) {
+	hx711.watchMutex.Lock()
+	defer hx711.watchMutex.Unlock()
+
+	for i := range hx711.watchers {
+		if hx711.watchers[i] == watcher {
+			hx711.watchers = append(hx711.watchers[:i], hx711.watchers[i+1:]...)
+			return
+		}
+	}
+}
+
+// updateWatchers updates all the added watchers with value
+func (hx711 *Hx711) updateWatchers(value float64, valueTime time.Time) {
+	hx711.watchMutex.Lock()
+	watchers := make([]*Watcher, len(hx711.watchers))
+	copy(watchers, hx711.watchers)
+	hx711.watchMutex.Unlock()
+
+	for i := range watchers {
+		watchers[i].Update(value, valueTime)
+	}
+}
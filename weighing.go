@@ -0,0 +1,310 @@
+package hx711
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Weighing is the record of one weighing
+type Weighing struct {
+	// ID is set by WeighingStore.Append
+	ID   uint64    `json:"id"`
+	Time time.Time `json:"time"`
+	// Gross is the weight on the scale
+	Gross float64 `json:"gross"`
+	// Tare is the container weight
+	Tare float64 `json:"tare"`
+	// Net is Gross minus Tare
+	Net  float64 `json:"net"`
+	Unit Unit    `json:"unit"`
+	// Stable is false if the reading did not settle before the timeout
+	Stable        bool   `json:"stable"`
+	CalibrationID string `json:"calibrationId,omitempty"`
+	Operator      string `json:"operator,omitempty"`
+	Product       string `json:"product,omitempty"`
+	// Volume is the liquid volume in liters of Net when Tank is set
+	Volume float64 `json:"volume,omitempty"`
+}
+
+// SetTare will get a Weight like ReadWeight and set Tare to it, so the net weight is zero.
+// Checkweighers and piece counters set up with SetupCheckweigher and SetupPieceCounter get the new tare.
+// Do not call Reset before or Shutdown after.
+// Reset and Shutdown are called for you.
+func (hx711 *Hx711) SetTare(numReadings int) error {
+	weight, err := hx711.ReadWeight(numReadings)
+	if err != nil {
+		return err
+	}
+	if weight.Overload || weight.Underload {
+		return fmt.Errorf("weight out of range")
+	}
+	hx711.Tare = weight.Value
+	return hx711.applyTare()
+}
+
+// NetWeight returns weight, which is in DisplayUnit, minus Tare, rounded to Division
+func (hx711 *Hx711) NetWeight(weight Weight) Weight {
+	weight.Value = RoundToDivision(weight.Value-hx711.Tare, weight.Division)
+	return weight
+}
+
+// newWeighing makes a Weighing from a calibrated value
+func (hx711 *Hx711) newWeighing(value float64, stable bool) (Weighing, error) {
+	gross, err := hx711.ToWeight(value)
+	if err != nil {
+		return Weighing{}, err
+	}
+	if gross.Overload {
+		return Weighing{}, fmt.Errorf("overload")
+	}
+	if gross.Underload {
+		return Weighing{}, fmt.Errorf("underload")
+	}
+	net := hx711.NetWeight(gross)
+
+	weighing := Weighing{
+		Time:          time.Now(),
+		Gross:         gross.Value,
+		Tare:          hx711.Tare,
+		Net:           net.Value,
+		Unit:          gross.Unit,
+		Stable:        stable,
+		CalibrationID: hx711.CalibrationID,
+	}
+	if hx711.product != nil {
+		weighing.Product = hx711.product.Name
+	}
+
+	if hx711.Tank != nil {
+		tankLevel, err := hx711.Tank.Level(net)
+		if err != nil {
+			return Weighing{}, fmt.Errorf("Tank Level error: %v", err)
+		}
+		weighing.Volume = tankLevel.Volume
+	}
+
+	return weighing, nil
+}
+
+// WeighingStore is an append-only store of Weighing records, optionally saved to a file.
+// It is safe to use from multiple goroutines.
+type WeighingStore struct {
+	mutex     sync.Mutex
+	weighings []Weighing
+	nextID    uint64
+	file      storeFile
+	// size is the length of the file after the last complete record
+	size int64
+}
+
+// storeFile is the file of a WeighingStore, an *os.File opened for appending
+type storeFile interface {
+	io.ReadWriteCloser
+	Sync() error
+	Truncate(size int64) error
+}
+
+// NewWeighingStore creates a WeighingStore kept in memory
+func NewWeighingStore() *WeighingStore {
+	return &WeighingStore{nextID: 1}
+}
+
+// OpenWeighingStore creates a WeighingStore saved to a file with one JSON record per line.
+// Records already in the file are loaded and new records are appended to it.
+// An incomplete last line, such as from a crash during Append, is removed from the file.
+func OpenWeighingStore(path string) (*WeighingStore, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("OpenFile error: %v", err)
+	}
+
+	weighingStore := &WeighingStore{nextID: 1, file: file}
+	err = weighingStore.load()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return weighingStore, nil
+}
+
+// load reads the records in the file, removing an incomplete last line
+// and making sure the file ends with a newline so the next record starts on its own line
+func (weighingStore *WeighingStore) load() error {
+	data, err := ioutil.ReadAll(weighingStore.file)
+	if err != nil {
+		return fmt.Errorf("ReadAll error: %v", err)
+	}
+
+	// good is the length of the file up to the end of the last good line
+	good := 0
+	line := 0
+	for start := 0; start < len(data); {
+		line++
+		end := bytes.IndexByte(data[start:], '\n')
+		next := len(data)
+		if end >= 0 {
+			end += start
+			next = end + 1
+		} else {
+			end = len(data)
+		}
+		text := bytes.TrimSpace(data[start:end])
+
+		if len(text) > 0 {
+			var weighing Weighing
+			err = json.Unmarshal(text, &weighing)
+			if err != nil {
+				if len(bytes.TrimSpace(data[next:])) > 0 {
+					return fmt.Errorf("line %v Unmarshal error: %v", line, err)
+				}
+				log.Print("hx711 WeighingStore removing incomplete last line ", line, ": ", err)
+				break
+			}
+			weighingStore.weighings = append(weighingStore.weighings, weighing)
+			if weighing.ID >= weighingStore.nextID {
+				weighingStore.nextID = weighing.ID + 1
+			}
+		}
+
+		good = next
+		start = next
+	}
+
+	if good < len(data) {
+		err = weighingStore.file.Truncate(int64(good))
+		if err != nil {
+			return fmt.Errorf("Truncate error: %v", err)
+		}
+	}
+	weighingStore.size = int64(good)
+	if good > 0 && data[good-1] != '\n' {
+		_, err = weighingStore.file.Write([]byte{'\n'})
+		if err != nil {
+			return fmt.Errorf("Write error: %v", err)
+		}
+		weighingStore.size++
+	}
+
+	return nil
+}
+
+// Close closes the file if there is one
+func (weighingStore *WeighingStore) Close() error {
+	weighingStore.mutex.Lock()
+	defer weighingStore.mutex.Unlock()
+
+	if weighingStore.file == nil {
+		return nil
+	}
+	err := weighingStore.file.Close()
+	weighingStore.file = nil
+	return err
+}
+
+// Append sets the ID of weighing to the next ID, stores it, and returns it with the ID.
+// If the store has a file, the record is written and synced to the file before it is stored.
+// If writing or syncing fails, the record is removed from the file and not stored.
+func (weighingStore *WeighingStore) Append(weighing Weighing) (Weighing, error) {
+	weighingStore.mutex.Lock()
+	defer weighingStore.mutex.Unlock()
+
+	weighing.ID = weighingStore.nextID
+	if weighing.Time.IsZero() {
+		weighing.Time = time.Now()
+	}
+
+	if weighingStore.file != nil {
+		data, err := json.Marshal(weighing)
+		if err != nil {
+			return Weighing{}, fmt.Errorf("Marshal error: %v", err)
+		}
+		_, err = weighingStore.file.Write(append(data, '\n'))
+		if err != nil {
+			// remove any partly written record so the next record starts on its own line
+			weighingStore.file.Truncate(weighingStore.size)
+			return Weighing{}, fmt.Errorf("Write error: %v", err)
+		}
+		err = weighingStore.file.Sync()
+		if err != nil {
+			// the record is not stored, so remove it from the file or the next record would get the same ID
+			weighingStore.file.Truncate(weighingStore.size)
+			return Weighing{}, fmt.Errorf("Sync error: %v", err)
+		}
+		weighingStore.size += int64(len(data) + 1)
+	}
+
+	weighingStore.weighings = append(weighingStore.weighings, weighing)
+	weighingStore.nextID++
+	return weighing, nil
+}
+
+// Get returns the weighing with id and true if there is one
+func (weighingStore *WeighingStore) Get(id uint64) (Weighing, bool) {
+	weighingStore.mutex.Lock()
+	defer weighingStore.mutex.Unlock()
+
+	for i := range weighingStore.weighings {
+		if weighingStore.weighings[i].ID == id {
+			return weighingStore.weighings[i], true
+		}
+	}
+	return Weighing{}, false
+}
+
+// Weighings returns a copy of all the weighings in the order they were appended
+func (weighingStore *WeighingStore) Weighings() []Weighing {
+	weighingStore.mutex.Lock()
+	defer weighingStore.mutex.Unlock()
+
+	weighings := make([]Weighing, len(weighingStore.weighings))
+	copy(weighings, weighingStore.weighings)
+	return weighings
+}
+
+// WriteJSON writes all the weighings to writer as a JSON array
+func (weighingStore *WeighingStore) WriteJSON(writer io.Writer) error {
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "\t")
+	return encoder.Encode(weighingStore.Weighings())
+}
+
+// WriteCSV writes all the weighings to writer as CSV with a header row
+func (weighingStore *WeighingStore) WriteCSV(writer io.Writer) error {
+	csvWriter := csv.NewWriter(writer)
+	err := csvWriter.Write([]string{"id", "time", "gross", "tare", "net", "unit", "stable", "calibration_id", "operator", "product", "volume"})
+	if err != nil {
+		return err
+	}
+
+	for _, weighing := range weighingStore.Weighings() {
+		err = csvWriter.Write([]string{
+			strconv.FormatUint(weighing.ID, 10),
+			weighing.Time.Format(time.RFC3339Nano),
+			strconv.FormatFloat(weighing.Gross, 'f', -1, 64),
+			strconv.FormatFloat(weighing.Tare, 'f', -1, 64),
+			strconv.FormatFloat(weighing.Net, 'f', -1, 64),
+			weighing.Unit.String(),
+			strconv.FormatBool(weighing.Stable),
+			weighing.CalibrationID,
+			weighing.Operator,
+			weighing.Product,
+			strconv.FormatFloat(weighing.Volume, 'f', -1, 64),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
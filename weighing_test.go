@@ -0,0 +1,241 @@
+package hx711
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// weighingLine returns a weighing as a line of a store file
+func weighingLine(t *testing.T, weighing Weighing) string {
+	data, err := json.Marshal(weighing)
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+	return string(data) + "\n"
+}
+
+func TestOpenWeighingStore(t *testing.T) {
+	first := Weighing{ID: 1, Time: testStart, Gross: 12.5, Net: 12.5, Unit: Kilogram, Stable: true}
+	second := Weighing{ID: 2, Time: testStart, Gross: 20, Tare: 1.5, Net: 18.5, Unit: Kilogram, Stable: true, Operator: "a,b"}
+	firstLine := weighingLine(t, first)
+	secondLine := weighingLine(t, second)
+
+	tests := []struct {
+		name    string
+		content string
+		wantIDs []uint64
+		wantErr bool
+	}{
+		{name: "empty", content: "", wantIDs: nil},
+		{name: "complete", content: firstLine + secondLine, wantIDs: []uint64{1, 2}},
+		{name: "half written last line", content: firstLine + secondLine[:len(secondLine)/2], wantIDs: []uint64{1}},
+		{name: "only half written line", content: firstLine[:10], wantIDs: nil},
+		{name: "last line without newline", content: firstLine + strings.TrimSuffix(secondLine, "\n"), wantIDs: []uint64{1, 2}},
+		{name: "blank lines", content: firstLine + "\n" + secondLine + "\n", wantIDs: []uint64{1, 2}},
+		{name: "bad line in the middle", content: firstLine[:10] + "\n" + secondLine, wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "weighings")
+			if err != nil {
+				t.Fatalf("TempDir error: %v", err)
+			}
+			defer os.RemoveAll(dir)
+			path := filepath.Join(dir, "weighings.jsonl")
+			err = ioutil.WriteFile(path, []byte(test.content), 0644)
+			if err != nil {
+				t.Fatalf("WriteFile error: %v", err)
+			}
+
+			weighingStore, err := OpenWeighingStore(path)
+			if test.wantErr {
+				if err == nil {
+					weighingStore.Close()
+					t.Fatal("OpenWeighingStore expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("OpenWeighingStore error: %v", err)
+			}
+
+			var ids []uint64
+			for _, weighing := range weighingStore.Weighings() {
+				ids = append(ids, weighing.ID)
+			}
+			if len(ids) != len(test.wantIDs) {
+				t.Fatalf("IDs got %v want %v", ids, test.wantIDs)
+			}
+			for i := range ids {
+				if ids[i] != test.wantIDs[i] {
+					t.Fatalf("IDs got %v want %v", ids, test.wantIDs)
+				}
+			}
+
+			appended, err := weighingStore.Append(Weighing{Net: 3, Unit: Gram})
+			if err != nil {
+				t.Fatalf("Append error: %v", err)
+			}
+			wantID := uint64(len(test.wantIDs) + 1)
+			if appended.ID != wantID {
+				t.Errorf("Append ID got %v want %v", appended.ID, wantID)
+			}
+			err = weighingStore.Close()
+			if err != nil {
+				t.Fatalf("Close error: %v", err)
+			}
+
+			// every line of the file is a whole record and it opens again with the new record
+			data, err := ioutil.ReadFile(path)
+			if err != nil {
+				t.Fatalf("ReadFile error: %v", err)
+			}
+			for _, line := range bytes.Split(bytes.TrimSpace(data), []byte("\n")) {
+				if len(bytes.TrimSpace(line)) == 0 {
+					continue
+				}
+				var weighing Weighing
+				err = json.Unmarshal(line, &weighing)
+				if err != nil {
+					t.Fatalf("line %q Unmarshal error: %v", line, err)
+				}
+			}
+
+			weighingStore, err = OpenWeighingStore(path)
+			if err != nil {
+				t.Fatalf("reopen error: %v", err)
+			}
+			defer weighingStore.Close()
+			reopened, ok := weighingStore.Get(wantID)
+			if !ok || reopened.Net != 3 || reopened.Unit != Gram {
+				t.Errorf("reopened got %+v %v", reopened, ok)
+			}
+		})
+	}
+}
+
+// failingFile is a store file that fails the next Write or Sync
+type failingFile struct {
+	storeFile
+	failWrite bool
+	failSync  bool
+}
+
+func (file *failingFile) Write(data []byte) (int, error) {
+	if file.failWrite {
+		file.failWrite = false
+		// partly written record
+		n, _ := file.storeFile.Write(data[:len(data)/2])
+		return n, fmt.Errorf("disk full")
+	}
+	return file.storeFile.Write(data)
+}
+
+func (file *failingFile) Sync() error {
+	if file.failSync {
+		file.failSync = false
+		return fmt.Errorf("sync failed")
+	}
+	return file.storeFile.Sync()
+}
+
+func TestWeighingStoreAppendError(t *testing.T) {
+	tests := []struct {
+		name      string
+		failWrite bool
+		failSync  bool
+	}{
+		{name: "write", failWrite: true},
+		{name: "sync", failSync: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "weighings")
+			if err != nil {
+				t.Fatalf("TempDir error: %v", err)
+			}
+			defer os.RemoveAll(dir)
+			path := filepath.Join(dir, "weighings.jsonl")
+
+			weighingStore, err := OpenWeighingStore(path)
+			if err != nil {
+				t.Fatalf("OpenWeighingStore error: %v", err)
+			}
+			_, err = weighingStore.Append(Weighing{Net: 1, Unit: Gram})
+			if err != nil {
+				t.Fatalf("Append error: %v", err)
+			}
+
+			file := &failingFile{storeFile: weighingStore.file, failWrite: test.failWrite, failSync: test.failSync}
+			weighingStore.file = file
+			_, err = weighingStore.Append(Weighing{Net: 2, Unit: Gram})
+			if err == nil {
+				t.Fatal("Append expected error")
+			}
+
+			// the failed record is not in the store or the file, so IDs are not used twice
+			appended, err := weighingStore.Append(Weighing{Net: 3, Unit: Gram})
+			if err != nil {
+				t.Fatalf("Append error: %v", err)
+			}
+			if appended.ID != 2 {
+				t.Errorf("Append ID got %v want 2", appended.ID)
+			}
+			err = weighingStore.Close()
+			if err != nil {
+				t.Fatalf("Close error: %v", err)
+			}
+
+			weighingStore, err = OpenWeighingStore(path)
+			if err != nil {
+				t.Fatalf("reopen error: %v", err)
+			}
+			defer weighingStore.Close()
+			weighings := weighingStore.Weighings()
+			if len(weighings) != 2 || weighings[0].Net != 1 || weighings[1].ID != 2 || weighings[1].Net != 3 {
+				t.Errorf("reopened got %+v", weighings)
+			}
+		})
+	}
+}
+
+func TestWeighingStoreExport(t *testing.T) {
+	weighingStore := NewWeighingStore()
+	_, err := weighingStore.Append(Weighing{Time: testStart, Gross: 20, Tare: 1.5, Net: 18.5, Unit: Kilogram, Stable: true, Operator: "a,b", Product: "gravel"})
+	if err != nil {
+		t.Fatalf("Append error: %v", err)
+	}
+
+	var buffer bytes.Buffer
+	err = weighingStore.WriteCSV(&buffer)
+	if err != nil {
+		t.Fatalf("WriteCSV error: %v", err)
+	}
+	want := "id,time,gross,tare,net,unit,stable,calibration_id,operator,product,volume\n" +
+		"1,2020-01-01T12:00:00Z,20,1.5,18.5,kg,true,,\"a,b\",gravel,0\n"
+	if buffer.String() != want {
+		t.Errorf("WriteCSV got %q want %q", buffer.String(), want)
+	}
+
+	buffer.Reset()
+	err = weighingStore.WriteJSON(&buffer)
+	if err != nil {
+		t.Fatalf("WriteJSON error: %v", err)
+	}
+	var weighings []Weighing
+	err = json.Unmarshal(buffer.Bytes(), &weighings)
+	if err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	if len(weighings) != 1 || weighings[0].Net != 18.5 || weighings[0].Unit != Kilogram || weighings[0].Product != "gravel" {
+		t.Errorf("WriteJSON got %+v", weighings)
+	}
+}